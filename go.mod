@@ -2,4 +2,4 @@ module github.com/beezzlot/go-magist-repos2
 
 go 1.25.5
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,52 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Аннотации ресурса, которыми владелец манифеста меняет проверки (при --honor-annotations)
+const (
+	annotationProfile      = "validator.bigbrother.io/profile"
+	annotationDisableRules = "validator.bigbrother.io/disable-rules"
+)
+
+// documentOverrides читает аннотации документа: профиль и отключённые идентификаторы правил.
+// Пустой профиль — без переопределения; некорректные значения попадают в errs.
+func documentOverrides(top *yaml.Node, errs *[]ValidationError) (string, []string) {
+	_, meta := getMap(top, "metadata")
+	_, ann := getMap(meta, "annotations")
+	profile := ""
+	if _, p := getMap(ann, annotationProfile); p != nil && p.Kind == yaml.ScalarNode {
+		if contains(profiles, p.Value) {
+			profile = p.Value
+		} else {
+			*errs = append(*errs, errAt(p, fmt.Sprintf("metadata.annotations.%s has unsupported value '%s'", annotationProfile, p.Value)))
+		}
+	}
+	var disabled []string
+	if _, d := getMap(ann, annotationDisableRules); d != nil && d.Kind == yaml.ScalarNode {
+		for _, id := range strings.Split(d.Value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				disabled = append(disabled, id)
+			}
+		}
+	}
+	return profile, disabled
+}
+
+// dropDisabled убирает из errs[from:] находки отключённых правил.
+func dropDisabled(errs []ValidationError, from int, disabled []string) []ValidationError {
+	if len(disabled) == 0 {
+		return errs
+	}
+	kept := errs[:from]
+	for _, e := range errs[from:] {
+		if !contains(disabled, RuleID(e)) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Потокобезопасность. Конфиг, зарегистрированные kind и правила — общее состояние пакета.
+// Поэтому ValidateFile и ValidateFileCached можно вызывать из нескольких горутин, но проверки
+// выполняются по одной под libraryMu. LoadConfig и RegisterKind берут ту же блокировку и не
+// меняют конфиг посреди чужой проверки, CacheKey — чтобы не читать его посреди замены.
+// Кэши схем и образов защищены собственными мьютексами. stdin ("-") библиотека не читает.
+// Флаги CLI (--timings, --per-doc-timeout) задают глобальные настройки и в библиотеке
+// не используются.
+var libraryMu sync.Mutex
+
+// errStdinPath — путь "-": общий stdin из нескольких горутин читался бы вперемешку
+var errStdinPath = errors.New(`path "-" (stdin) is not supported by the library`)
+
+// registeredKinds — kind из RegisterKind: apiVersion и имя функции проверки, для ключа кэша
+var registeredKinds = map[string]string{}
+
+// GroupVersionKind задаёт apiVersion и kind объекта, например {"apps/v1", "Deployment"}.
+type GroupVersionKind struct {
+	APIVersion string
+	Kind       string
+}
+
+// KindValidator проверяет объект целиком. apiVersion, kind и metadata к моменту вызова
+// уже проверены ядром; находки добавляются в errs через ErrAt/WarnAt.
+type KindValidator func(obj *yaml.Node, errs *[]ValidationError)
+
+// RegisterKind добавляет проверку для kind, которого нет в ядре, или заменяет встроенную.
+// Вызывать до Main/ValidateFile, обычно из init.
+func RegisterKind(gvk GroupVersionKind, v KindValidator) {
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	kinds[gvk.Kind] = kindValidator{validateObject: v}
+	apiVersions[gvk.Kind] = []string{gvk.APIVersion}
+	registeredKinds[gvk.Kind] = gvk.APIVersion + " " + runtime.FuncForPC(reflect.ValueOf(v).Pointer()).Name()
+}
+
+// LoadConfig делает действующим конфиг file для следующих проверок; "" — .validator.yaml
+// текущего каталога, если он есть. Ошибки в самом конфиге — *ConfigError.
+func LoadConfig(file string) error {
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	return loadConfig(file)
+}
+
+// ValidateFile проверяет все документы файла, включая проверки между документами этого файла.
+// Ошибка разбора — *ParseError (errors.Is(err, ErrNotYAML), если документ не mapping).
+// Безопасна для параллельного вызова; проверки идут по одной. Путь "-" (stdin) не принимается.
+func ValidateFile(path string) ([]ValidationError, error) {
+	if path == "-" {
+		return nil, errStdinPath
+	}
+	m, err := readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	validateManifest(m)
+	validateDocumentSet([]*manifest{m})
+	fingerprintFindings(m)
+	resolveRanges(m.src, m.errs)
+	return m.errs, nil
+}
+
+// GetMap возвращает ключ и значение по имени ключа mapping-узла или nil, nil.
+func GetMap(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	return getMap(m, key)
+}
+
+// ExpectType добавляет ошибку "<field> must be <type>", если узел другого вида.
+func ExpectType(node *yaml.Node, kind yaml.Kind, field string, errs *[]ValidationError) bool {
+	return expectType(node, kind, field, errs)
+}
+
+// ErrAt создаёт ошибку с позицией узла.
+func ErrAt(n *yaml.Node, msg string) ValidationError {
+	return errAt(n, msg)
+}
+
+// WarnAt создаёт предупреждение с позицией узла.
+func WarnAt(n *yaml.Node, msg string) ValidationError {
+	return warnAt(n, msg)
+}
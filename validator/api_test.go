@@ -0,0 +1,182 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func validateTestWidget(obj *yaml.Node, errs *[]ValidationError) {}
+
+func validateTestGadget(obj *yaml.Node, errs *[]ValidationError) {}
+
+func TestCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	writeContext := func(name, src string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	prodContext := writeContext("prod.yaml", "namespaces: [prod]\n")
+	stageContext := writeContext("stage.yaml", "namespaces: [stage]\n")
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T)
+	}{
+		{"register kind", func(t *testing.T) {
+			registerTestKind(t, "Widget", validateTestWidget)
+		}},
+		{"replace kind validator", func(t *testing.T) {
+			registerTestKind(t, "Widget", validateTestGadget)
+		}},
+		{"context", func(t *testing.T) {
+			if err := loadContext(prodContext); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"context contents", func(t *testing.T) {
+			if err := loadContext(stageContext); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"config", func(t *testing.T) {
+			config.HonorAnnotations = !config.HonorAnnotations
+		}},
+	}
+	content := []byte("apiVersion: v1\nkind: Pod\n")
+	resetState()
+	t.Cleanup(resetState)
+	keys := map[string]string{}
+	base, err := CacheKey(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys[base] = "initial state"
+	// каждый шаг меняет состояние поверх предыдущих и должен дать новый ключ
+	for _, tt := range tests {
+		tt.setup(t)
+		key, err := CacheKey(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev, ok := keys[key]; ok {
+			t.Errorf("%s: key equals the one for %s", tt.name, prev)
+		}
+		keys[key] = tt.name
+		if again, _ := CacheKey(content); again != key {
+			t.Errorf("%s: key is not stable: %s != %s", tt.name, again, key)
+		}
+	}
+}
+
+type mapCache struct {
+	mu sync.Mutex
+	m  map[string][]ValidationError
+}
+
+func (c *mapCache) Get(key string) ([]ValidationError, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	errs, ok := c.m[key]
+	return errs, ok
+}
+
+func (c *mapCache) Put(key string, errs []ValidationError) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = errs
+	return nil
+}
+
+// Запускать с -race: библиотечные вызовы из разных горутин не должны гоняться за конфигом.
+func TestLibraryConcurrentCalls(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "pod.yaml")
+	if err := os.WriteFile(manifest, []byte(testPod("app", "Vyp")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.yml")
+	if err := os.WriteFile(configPath, []byte("honorAnnotations: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resetState()
+	t.Cleanup(resetState)
+	cache := &mapCache{m: map[string][]ValidationError{}}
+	calls := []func() error{
+		func() error { _, err := ValidateFile(manifest); return err },
+		func() error { _, err := ValidateFileCached(manifest, cache); return err },
+		func() error { return LoadConfig(configPath) },
+		func() error { _, err := CacheKey([]byte("kind: Pod\n")); return err },
+	}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		for _, call := range calls {
+			wg.Go(func() {
+				if err := call(); err != nil {
+					t.Errorf("call %d: %v", i, err)
+				}
+			})
+		}
+	}
+	wg.Wait()
+	errs, err := ValidateFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) == 0 {
+		t.Error("no findings for a pod with os 'Vyp'")
+	}
+}
+
+// Библиотека не читает stdin: вызовы из разных горутин делили бы один поток.
+func TestLibraryRejectsStdin(t *testing.T) {
+	if _, err := ValidateFile("-"); !errors.Is(err, errStdinPath) {
+		t.Errorf("ValidateFile(\"-\") error = %v, want %v", err, errStdinPath)
+	}
+	if _, err := ValidateFileCached("-", &mapCache{m: map[string][]ValidationError{}}); !errors.Is(err, errStdinPath) {
+		t.Errorf("ValidateFileCached(\"-\") error = %v, want %v", err, errStdinPath)
+	}
+}
+
+// ValidateFile и ValidateFileCached (с пустым и заполненным кэшем) дают одни и те же находки.
+func TestValidateFileMatchesCached(t *testing.T) {
+	resetState()
+	t.Cleanup(resetState)
+	file := writeTestFile(t, t.TempDir(), "pod.yaml", testPod("app", "Vyp"))
+	exported := func(errs []ValidationError, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(errs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	plain := exported(ValidateFile(file))
+	if !strings.Contains(plain, `"Fingerprint":"`) {
+		t.Errorf("ValidateFile findings have no fingerprint: %s", plain)
+	}
+	cache := &mapCache{m: map[string][]ValidationError{}}
+	for _, run := range []string{"cold", "warm"} {
+		if cached := exported(ValidateFileCached(file, cache)); cached != plain {
+			t.Errorf("%s cache: %s\nValidateFile: %s", run, cached, plain)
+		}
+	}
+}
+
+func TestValidatorBuildInCacheKey(t *testing.T) {
+	if b := validatorBuild(); !strings.Contains(b, "github.com/beezzlot/go-magist-repos2") {
+		t.Errorf("validatorBuild() = %q, want the validator module", b)
+	}
+}
@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// apiVersions — в каких apiVersion API-сервер обслуживает kind. Для проверяемых kind
+// это версия из kinds; остальные нужны, чтобы подсказать верную пару.
+var apiVersions = map[string][]string{
+	"Pod":                      {"v1"},
+	"Service":                  {"v1"},
+	"ServiceAccount":           {"v1"},
+	"Secret":                   {"v1"},
+	"ConfigMap":                {"v1"},
+	"Namespace":                {"v1"},
+	"PersistentVolumeClaim":    {"v1"},
+	"PersistentVolume":         {"v1"},
+	"LimitRange":               {"v1"},
+	"ResourceQuota":            {"v1"},
+	"Deployment":               {"apps/v1"},
+	"StatefulSet":              {"apps/v1"},
+	"DaemonSet":                {"apps/v1"},
+	"ReplicaSet":               {"apps/v1"},
+	"Job":                      {"batch/v1"},
+	"CronJob":                  {"batch/v1"},
+	"HorizontalPodAutoscaler":  {"autoscaling/v2"},
+	"Ingress":                  {"networking.k8s.io/v1"},
+	"IngressClass":             {"networking.k8s.io/v1"},
+	"NetworkPolicy":            {"networking.k8s.io/v1"},
+	"PodDisruptionBudget":      {"policy/v1"},
+	"PriorityClass":            {"scheduling.k8s.io/v1"},
+	"StorageClass":             {"storage.k8s.io/v1"},
+	"Role":                     {rbacAPIVersion},
+	"ClusterRole":              {rbacAPIVersion},
+	"RoleBinding":              {rbacAPIVersion},
+	"ClusterRoleBinding":       {rbacAPIVersion},
+	"CustomResourceDefinition": {"apiextensions.k8s.io/v1"},
+}
+
+// validateAPIVersion сверяет пару apiVersion/kind с таблицей и apiVersions конфига:
+// несовпадение для известного kind — предупреждение о конфликте с подсказкой верной версии;
+// неизвестная версия — неподдерживаемое значение.
+func validateAPIVersion(apiNode *yaml.Node, kind string, errs *[]ValidationError) {
+	v := apiNode.Value
+	versions, ok := config.APIVersions[kind]
+	if !ok {
+		versions, ok = apiVersions[kind]
+	}
+	switch {
+	case ok && contains(versions, v):
+	case !knownAPIVersion(v):
+		// неизвестная версия — прежнее сообщение, даже если kind известен (apiVersion: v2 у Pod)
+		*errs = append(*errs, errAt(apiNode, fmt.Sprintf("apiVersion has unsupported value '%s'", v)))
+	case ok:
+		e := warnAt(apiNode, fmt.Sprintf("apiVersion '%s' conflicts with kind '%s'", v, kind))
+		e.Hint = fmt.Sprintf("%s is served by %s", kind, strings.Join(versions, ", "))
+		*errs = append(*errs, e)
+	}
+}
+
+// knownAPIVersion — версию обслуживает хоть один kind таблицы или конфига.
+func knownAPIVersion(v string) bool {
+	for _, versions := range config.APIVersions {
+		if contains(versions, v) {
+			return true
+		}
+	}
+	for _, versions := range apiVersions {
+		if contains(versions, v) {
+			return true
+		}
+	}
+	return false
+}
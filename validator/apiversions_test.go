@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateAPIVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		configured map[string][]string
+		wantMsg    string
+		wantHint   string
+		wantWarn   bool
+	}{
+		{name: "served", apiVersion: "v1", kind: "Pod"},
+		{name: "unknown version of known kind", apiVersion: "v2", kind: "Pod", wantMsg: "apiVersion has unsupported value 'v2'"},
+		{name: "known version of another kind", apiVersion: "apps/v1", kind: "Pod", wantMsg: "apiVersion 'apps/v1' conflicts with kind 'Pod'", wantHint: "Pod is served by v1", wantWarn: true},
+		{name: "unknown kind, known version", apiVersion: "v1", kind: "Widget"},
+		{name: "unknown kind and version", apiVersion: "example.com/v1", kind: "Widget", wantMsg: "apiVersion has unsupported value 'example.com/v1'"},
+		{name: "wildcard from config", apiVersion: "example.com/v1", kind: "Widget", configured: map[string][]string{"*": {"example.com/v1"}}},
+		{name: "kind from config", apiVersion: "example.com/v1", kind: "Widget", configured: map[string][]string{"Widget": {"example.com/v1"}}},
+		{name: "configured version of another kind", apiVersion: "example.com/v1", kind: "Pod", configured: map[string][]string{"Widget": {"example.com/v1"}}, wantMsg: "apiVersion 'example.com/v1' conflicts with kind 'Pod'", wantHint: "Pod is served by v1", wantWarn: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetState()
+			config.APIVersions = tt.configured
+			var errs []ValidationError
+			validateAPIVersion(&yaml.Node{Kind: yaml.ScalarNode, Value: tt.apiVersion, Line: 1}, tt.kind, &errs)
+			if tt.wantMsg == "" {
+				if len(errs) != 0 {
+					t.Fatalf("unexpected findings: %+v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("got %d findings, want 1: %+v", len(errs), errs)
+			}
+			if errs[0].Msg != tt.wantMsg || errs[0].Hint != tt.wantHint {
+				t.Errorf("got %q (hint %q), want %q (hint %q)", errs[0].Msg, errs[0].Hint, tt.wantMsg, tt.wantHint)
+			}
+			if warn := errs[0].Severity == SeverityWarning; warn != tt.wantWarn {
+				t.Errorf("warning = %v, want %v", warn, tt.wantWarn)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// runArgoCD — подкоманда argocd: проверка в генерации манифестов Argo CD. Подходит и как
+// генератор config management plugin (kustomize build . | validator argocd), и для вывода
+// argocd app manifests. Без ошибок манифесты выводятся в w без изменений, и Argo CD
+// применяет их; при ошибках в w не пишется ничего, находки уходят в stderr, код 1 —
+// Argo CD показывает stderr генератора как ошибку сравнения, и синхронизация не начинается.
+func runArgoCD(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("argocd", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s argocd [flags] [file...]\n", filepath.Base(os.Args[0]))
+		fmt.Fprintln(os.Stderr, "Without files the manifests are read from stdin.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(os.Stderr, configFile(*configPath), err)
+		return 1
+	}
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			printIOErr(os.Stderr, config.RulesDir, err)
+			return 1
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			printIOErr(os.Stderr, config.Rules, err)
+			return 1
+		}
+	}
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	var manifests []*manifest
+	for _, file := range files {
+		m, err := readManifest(file)
+		if err != nil {
+			printIOErr(os.Stderr, file, err)
+			return 1
+		}
+		manifests = append(manifests, m)
+	}
+	for _, m := range manifests {
+		validateManifest(m)
+	}
+	validateDocumentSet(manifests)
+	index := resourceIndex{}
+	errCount := 0
+	for _, m := range manifests {
+		index.add(m)
+		finishManifest(os.Stderr, m, false)
+		applyMessageTemplates(m)
+		printErrors(os.Stderr, m)
+		for _, e := range m.errs {
+			if e.Severity != SeverityWarning {
+				errCount++
+			}
+		}
+	}
+	if errCount > 0 {
+		fmt.Fprintf(os.Stderr, "%d error(s): manifests rejected by validator\n", errCount)
+		return 1
+	}
+	var out bytes.Buffer
+	for i, m := range manifests {
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(m.src)
+		if len(m.src) > 0 && m.src[len(m.src)-1] != '\n' {
+			out.WriteByte('\n')
+		}
+	}
+	w.Write(out.Bytes())
+	return 0
+}
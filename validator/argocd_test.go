@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+// Плагин Argo CD отдаёт манифесты в stdout только без ошибок: иначе stdout пуст и код 1.
+func TestRunArgoCD(t *testing.T) {
+	dir := t.TempDir()
+	good := writeTestFile(t, dir, "good.yaml", testPod("good", "linux"))
+	other := writeTestFile(t, dir, "other.yaml", testPod("other", "windows"))
+	bad := writeTestFile(t, dir, "bad.yaml", testPod("bad", "Vyp"))
+	configPath := teamRulesConfig(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{"single file", []string{good}, 0, testPod("good", "linux")},
+		{"files joined as a stream", []string{good, other}, 0, testPod("good", "linux") + "---\n" + testPod("other", "windows")},
+		{"errors reject every file", []string{good, bad}, 1, ""},
+		{"rules from config", []string{"--config", configPath, good}, 1, ""},
+		{"unreadable file", []string{filepath.Join(dir, "missing.yaml")}, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetState()
+			var out bytes.Buffer
+			code := runArgoCD(tt.args, &out)
+			if code != tt.wantCode {
+				t.Errorf("code = %d, want %d", code, tt.wantCode)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("stdout:\n%s\nwant:\n%s", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
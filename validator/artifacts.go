@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// parseChecksums читает список закреплённых сумм в формате sha256sum:
+// "<sha256>  <url или oci://-ссылка>"; префикс sha256: у суммы допускается.
+func parseChecksums(file string) (map[string]string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	sums := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		sum := strings.TrimPrefix(fields[0], "sha256:")
+		if len(fields) != 2 || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("line %d has invalid format", line)
+		}
+		if _, err := hex.DecodeString(sum); err != nil {
+			return nil, fmt.Errorf("line %d has invalid format", line)
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(sum)
+	}
+	return sums, sc.Err()
+}
+
+// verifyChecksum сверяет скачанный артефакт с закреплённой суммой; при requireChecksums
+// артефакт без суммы не принимается.
+func verifyChecksum(name string, data []byte) error {
+	want, ok := config.checksums[name]
+	if !ok {
+		if config.RequireChecksums {
+			return fmt.Errorf("%s has no pinned checksum", name)
+		}
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("%s does not match pinned checksum sha256:%s (got sha256:%s)", name, want, got)
+	}
+	return nil
+}
+
+// parseCosignKey читает открытый ключ cosign (PEM, ECDSA).
+func parseCosignKey(file string) (*ecdsa.PublicKey, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("cosign public key has invalid format")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("cosign public key must be ECDSA")
+	}
+	return key, nil
+}
+
+// verifyCosignBlob проверяет подпись "cosign sign-blob --key": base64 подписи ECDSA
+// над SHA-256 содержимого.
+func verifyCosignBlob(name string, data, sig []byte) error {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("%s signature has invalid format", name)
+	}
+	sum := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(config.cosignKey, sum[:], raw) {
+		return fmt.Errorf("%s signature verification failed", name)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// cosignSign подписывает data так же, как cosign sign-blob --key.
+func cosignSign(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	t.Helper()
+	sum := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Скачанная схема принимается, только если совпадает закреплённая сумма и подпись cosign.
+func TestFetchSchemaVerifiesArtifacts(t *testing.T) {
+	schema := []byte(`{"type": "object"}`)
+	tampered := []byte(`{"type": "objecT"}`)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDir := t.TempDir()
+	pubKey := writeTestFile(t, keyDir, "cosign.pub", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+
+	tests := []struct {
+		name string
+		// файлы сервера по путям
+		files   map[string][]byte
+		pin     []byte
+		require bool
+		cosign  bool
+		wantErr string
+	}{
+		{name: "pinned checksum", files: map[string][]byte{"/pod.json": schema}, pin: schema},
+		{name: "wrong checksum", files: map[string][]byte{"/pod.json": tampered}, pin: schema, wantErr: "does not match pinned checksum sha256:" + sha256Hex(schema)},
+		{name: "required checksum missing", files: map[string][]byte{"/pod.json": schema}, require: true, wantErr: "has no pinned checksum"},
+		{name: "cosign signature", files: map[string][]byte{"/pod.json": schema, "/pod.json.sig": cosignSign(t, key, schema)}, cosign: true},
+		{name: "one byte changed after signing", files: map[string][]byte{"/pod.json": tampered, "/pod.json.sig": cosignSign(t, key, schema)}, cosign: true, wantErr: "signature verification failed"},
+		{name: "signature missing", files: map[string][]byte{"/pod.json": schema}, cosign: true, wantErr: "signature not found"},
+		{name: "signature garbage", files: map[string][]byte{"/pod.json": schema, "/pod.json.sig": []byte("not base64!")}, cosign: true, wantErr: "signature has invalid format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, ok := tt.files[r.URL.Path]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write(b)
+			}))
+			defer srv.Close()
+
+			dir := t.TempDir()
+			cfg := "schemaLocations: [" + srv.URL + "/pod.json]\n"
+			if tt.pin != nil {
+				sums := writeTestFile(t, dir, "SHA256SUMS", sha256Hex(tt.pin)+"  "+srv.URL+"/pod.json\n")
+				cfg += "artifactChecksums: " + sums + "\n"
+			}
+			if tt.require {
+				cfg += "requireChecksums: true\n"
+			}
+			if tt.cosign {
+				cfg += "cosignPublicKey: " + pubKey + "\n"
+			}
+			resetState()
+			t.Cleanup(resetState)
+			if err := loadConfig(writeTestFile(t, dir, "config.yml", cfg)); err != nil {
+				t.Fatal(err)
+			}
+			b, err := fetchSchema("Pod", "v1")
+			if tt.wantErr == "" {
+				if err != nil || string(b) != string(schema) {
+					t.Errorf("fetchSchema = %q, %v; want the schema", b, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("fetchSchema error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Копия схемы в кэше проверяется так же, как скачанная: подменённый кэш не принимается.
+func TestFetchSchemaRejectsTamperedCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	schema := []byte(`{"type": "object"}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(schema)
+	}))
+	url := srv.URL + "/pod.json"
+	dir := t.TempDir()
+	sums := writeTestFile(t, dir, "SHA256SUMS", sha256Hex(schema)+"  "+url+"\n")
+	resetState()
+	t.Cleanup(resetState)
+	if err := loadConfig(writeTestFile(t, dir, "config.yml", "schemaLocations: ["+url+"]\nartifactChecksums: "+sums+"\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fetchSchema("Pod", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	// реестр недоступен, в кэше — изменённая копия
+	srv.Close()
+	if err := os.WriteFile(schemaCachePath(url), []byte(`{"type": "objecT"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fetchSchema("Pod", "v1"); err == nil || !strings.Contains(err.Error(), "does not match pinned checksum") {
+		t.Errorf("fetchSchema error = %v, want a checksum mismatch", err)
+	}
+}
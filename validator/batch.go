@@ -0,0 +1,165 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Форматы --stdin-batch
+const (
+	batchJSON = "json"
+	batchNUL  = "nul"
+)
+
+// batchRecord — один буфер на проверку в режиме --stdin-batch=json
+type batchRecord struct {
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+}
+
+// batchResult — ответ на запись в режиме --stdin-batch=json, по одной строке JSON
+type batchResult struct {
+	Filename string        `json:"filename"`
+	Error    string        `json:"error,omitempty"`
+	Findings []jsonFinding `json:"findings"`
+}
+
+type jsonFinding struct {
+	Line      int `json:"line,omitempty"`
+	Column    int `json:"column,omitempty"`
+	EndLine   int `json:"endLine,omitempty"`
+	EndColumn int `json:"endColumn,omitempty"`
+	// исходный файл, если находка перенесена с сгенерированного YAML
+	File     string `json:"file,omitempty"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	// путь к узлу для людей и JSON Pointer (RFC 6901) для программ, правящих документ;
+	// Document — номер документа в файле, от 0
+	Path        string `json:"path,omitempty"`
+	Pointer     string `json:"pointer,omitempty"`
+	Document    *int   `json:"document,omitempty"`
+	Hint        string `json:"hint,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Docs        string `json:"docs,omitempty"`
+}
+
+func toJSONFinding(e ValidationError) jsonFinding {
+	sev := "error"
+	if e.Severity == SeverityWarning {
+		sev = "warning"
+	}
+	f := jsonFinding{
+		Line: e.Line, Column: e.Column, EndLine: e.EndLine, EndColumn: e.EndColumn, File: e.File,
+		Severity: sev, Rule: RuleID(e), Message: e.Msg, Hint: e.Hint,
+		Fingerprint: e.Fingerprint, Docs: docURL(RuleID(e)),
+	}
+	if e.Path != nil {
+		f.Path, f.Pointer, f.Document = e.Path.String(), e.Path.Pointer(), &e.Document
+	}
+	return f
+}
+
+// runBatch проверяет поток записей {filename, content} из r и пишет находки по каждой в w.
+// json: записи — JSON-объекты подряд, ответ — строка JSON на запись.
+// nul: имя и содержимое разделены NUL, ответ — находки записи в текстовом виде и NUL.
+// Возвращает true, если хоть в одной записи есть ошибки.
+func runBatch(format string, r io.Reader, w io.Writer) (bool, error) {
+	next, err := batchReader(format, r)
+	if err != nil {
+		return false, err
+	}
+	failed := false
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+	for {
+		rec, err := next()
+		if errors.Is(err, io.EOF) {
+			return failed, nil
+		}
+		if err != nil {
+			return failed, err
+		}
+		m, perr := parseManifest(rec.Filename, []byte(rec.Content))
+		if perr == nil {
+			validateManifest(m)
+			validateDocumentSet([]*manifest{m})
+			fingerprintFindings(m)
+			applyExceptions(m)
+			applyMessageTemplates(m)
+			resolveRanges(m.src, m.errs)
+			applyOrigins(m)
+			for _, e := range m.errs {
+				if e.Severity != SeverityWarning {
+					failed = true
+				}
+			}
+		} else {
+			failed = true
+		}
+
+		if format == batchJSON {
+			res := batchResult{Filename: rec.Filename, Findings: []jsonFinding{}}
+			if perr != nil {
+				res.Error = perr.Error()
+			} else {
+				for _, e := range m.errs {
+					res.Findings = append(res.Findings, toJSONFinding(e))
+				}
+			}
+			b, _ := json.Marshal(res)
+			out.Write(append(b, '\n'))
+		} else {
+			if perr != nil {
+				fmt.Fprintf(out, "%s: %v\n", rec.Filename, perr)
+			} else {
+				for _, e := range m.errs {
+					fmt.Fprintln(out, formatFinding(findingFile(rec.Filename, e), e))
+				}
+			}
+			out.WriteByte(0)
+		}
+		// обёртка ждёт ответ, не закрывая stdin
+		out.Flush()
+	}
+}
+
+func batchReader(format string, r io.Reader) (func() (batchRecord, error), error) {
+	switch format {
+	case batchJSON:
+		dec := json.NewDecoder(r)
+		return func() (batchRecord, error) {
+			var rec batchRecord
+			err := dec.Decode(&rec)
+			return rec, err
+		}, nil
+	case batchNUL:
+		br := bufio.NewReader(r)
+		field := func() (string, error) {
+			s, err := br.ReadString(0)
+			if err != nil {
+				if errors.Is(err, io.EOF) && s != "" {
+					return "", io.ErrUnexpectedEOF
+				}
+				return "", err
+			}
+			return string(bytes.TrimSuffix([]byte(s), []byte{0})), nil
+		}
+		return func() (batchRecord, error) {
+			name, err := field()
+			if err != nil {
+				return batchRecord{}, err
+			}
+			content, err := field()
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return batchRecord{Filename: name, Content: content}, err
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown stdin-batch format '%s'", format)
+}
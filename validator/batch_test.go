@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func batchInput(t testing.TB, format string, n int) []byte {
+	t.Helper()
+	src, err := os.ReadFile("../test.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var in bytes.Buffer
+	for i := 0; i < n; i++ {
+		if format == batchJSON {
+			b, _ := json.Marshal(batchRecord{Filename: "test.yml", Content: string(src)})
+			in.Write(b)
+			continue
+		}
+		in.WriteString("test.yml\x00")
+		in.Write(src)
+		in.WriteByte(0)
+	}
+	return in.Bytes()
+}
+
+func TestRunBatch(t *testing.T) {
+	tests := []struct {
+		format string
+		sep    string
+		want   string
+	}{
+		{batchNUL, "\x00", "test.yml:10 os has unsupported value 'Vyp'\ntest.yml:24 port value out of range\n"},
+		{batchJSON, "\n", `"message":"port value out of range"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			resetState()
+			var out bytes.Buffer
+			failed, err := runBatch(tt.format, bytes.NewReader(batchInput(t, tt.format, 3)), &out)
+			if err != nil || !failed {
+				t.Fatalf("runBatch = %v, %v; want failed", failed, err)
+			}
+			responses := strings.Split(strings.TrimSuffix(out.String(), tt.sep), tt.sep)
+			if len(responses) != 3 {
+				t.Fatalf("got %d responses, want 3:\n%q", len(responses), out.String())
+			}
+			// одинаковые записи — одинаковые ответы: состояние между записями не протекает
+			for _, r := range responses {
+				if r != responses[0] {
+					t.Errorf("responses differ:\n%q\n%q", responses[0], r)
+				}
+				if !strings.Contains(r, tt.want) {
+					t.Errorf("response %q lacks %q", r, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRunBatch(b *testing.B) {
+	for _, format := range []string{batchJSON, batchNUL} {
+		b.Run(format, func(b *testing.B) {
+			resetState()
+			in := batchInput(b, format, 100)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(in)))
+			for i := 0; i < b.N; i++ {
+				if _, err := runBatch(format, bytes.NewReader(in), io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
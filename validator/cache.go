@@ -0,0 +1,184 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// версия формата записей кэша; меняется, когда меняется ValidationError
+const cacheFormat = "1"
+
+// validatorBuild — версия модуля валидатора из сборки (у встраивающей программы — из её
+// зависимостей) и ревизия VCS, если валидатор собран как главный модуль. Входит в ключ
+// кэша: после обновления, изменившего встроенные проверки, общий кэш не отдаёт старые находки.
+var validatorBuild = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	pkg := reflect.TypeFor[manifest]().PkgPath()
+	var parts []string
+	for _, m := range append([]*debug.Module{&info.Main}, info.Deps...) {
+		if m.Path != "" && (pkg == m.Path || strings.HasPrefix(pkg, m.Path+"/")) {
+			parts = append(parts, m.Path+"@"+m.Version+" "+m.Sum)
+		}
+	}
+	if pkg == info.Main.Path || strings.HasPrefix(pkg, info.Main.Path+"/") {
+		for _, st := range info.Settings {
+			if st.Key == "vcs.revision" || st.Key == "vcs.modified" {
+				parts = append(parts, st.Key+"="+st.Value)
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+})
+
+// Cache хранит находки проверенного содержимого. Ключ (см. CacheKey) учитывает хэш
+// содержимого, действующую конфигурацию, правила и версию валидатора, поэтому кэш можно
+// делить между запусками и машинами CI. Реализации должны допускать одновременный доступ.
+type Cache interface {
+	// Get возвращает находки по ключу; false — записи нет.
+	Get(key string) ([]ValidationError, bool)
+	// Put сохраняет находки по ключу.
+	Put(key string, errs []ValidationError) error
+}
+
+// CacheKey — ключ кэша для содержимого файла при текущей конфигурации, правилах, контексте
+// кластера, kind из RegisterKind и версии валидатора. Проверка kind учитывается по имени функции: изменив её
+// тело без переименования, очистите кэш.
+func CacheKey(content []byte) (string, error) {
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	return cacheKey(content)
+}
+
+// cacheKey — CacheKey под уже взятой libraryMu.
+func cacheKey(content []byte) (string, error) {
+	cfg, err := yaml.Marshal(&config)
+	if err != nil {
+		return "", err
+	}
+	rs, err := yaml.Marshal(rules)
+	if err != nil {
+		return "", err
+	}
+	ctx, err := yaml.Marshal(clusterContext)
+	if err != nil {
+		return "", err
+	}
+	var registered []byte
+	for _, kind := range slices.Sorted(maps.Keys(registeredKinds)) {
+		registered = fmt.Appendf(registered, "%s %s\n", kind, registeredKinds[kind])
+	}
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(cacheFormat), []byte(validatorBuild()), content, cfg, rs, ctx, registered} {
+		sum := sha256.Sum256(part)
+		h.Write(sum[:])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ValidateFileCached — ValidateFile с кэшем c. Находки из кэша не связаны с узлами YAML,
+// у них есть только экспортированные поля.
+func ValidateFileCached(path string, c Cache) ([]ValidationError, error) {
+	if path == "-" {
+		return nil, errStdinPath
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	key, err := cacheKey(b)
+	if err != nil {
+		return nil, err
+	}
+	if errs, ok := c.Get(key); ok {
+		return errs, nil
+	}
+	m, err := parseManifest(path, b)
+	if err != nil {
+		return nil, err
+	}
+	validateManifest(m)
+	validateDocumentSet([]*manifest{m})
+	fingerprintFindings(m)
+	resolveRanges(m.src, m.errs)
+	if err := c.Put(key, m.errs); err != nil {
+		return nil, err
+	}
+	return m.errs, nil
+}
+
+// FSCache — Cache в каталоге: запись на ключ, файл <ключ>.json. Запись идёт через
+// временный файл и rename, поэтому каталог можно делить между процессами (общий том CI).
+type FSCache struct {
+	Dir string
+}
+
+// NewFSCache создаёт каталог кэша, если его нет.
+func NewFSCache(dir string) (*FSCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FSCache{Dir: dir}, nil
+}
+
+func (c *FSCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
+
+// Get читает запись; повреждённая запись считается отсутствующей.
+func (c *FSCache) Get(key string) ([]ValidationError, bool) {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var errs []ValidationError
+	if err := json.Unmarshal(b, &errs); err != nil {
+		return nil, false
+	}
+	return errs, true
+}
+
+// Put записывает находки; параллельная запись того же ключа безопасна — побеждает последняя.
+func (c *FSCache) Put(key string, errs []ValidationError) error {
+	if errs == nil {
+		errs = []ValidationError{}
+	}
+	b, err := json.Marshal(errs)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), c.path(key)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
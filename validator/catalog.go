@@ -0,0 +1,135 @@
+package validator
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// catalogEntry — правило в выгрузке rules export
+type catalogEntry struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Severity    string   `json:"severity"`
+	Category    string   `json:"category"`
+	Categories  []string `json:"categories"`
+	Fixable     bool     `json:"fixable"`
+	Links       []string `json:"links,omitempty"`
+}
+
+// Встроенные проверки не перечисляются поимённо: их идентификатор — поле и категория
+// сообщения (см. RuleID), поэтому в каталоге они описаны шаблонами <field>.<category>.
+var builtinCatalog = []catalogEntry{
+	{ID: "<field>.required", Title: "Required field is missing", Category: "required"},
+	{ID: "<field>.empty", Title: "Field must not be empty", Category: "empty"},
+	{ID: "<field>.type", Title: "Field has a wrong type", Category: "type"},
+	{ID: "<field>.format", Title: "Value has invalid format", Category: "format"},
+	{ID: "<field>.enum", Title: "Value is not one of the supported values", Category: "enum"},
+	{ID: "<field>.range", Title: "Value is out of range", Category: "range"},
+	{ID: "<field>.forbidden", Title: "Field or value is not allowed", Category: "forbidden"},
+	{ID: "<field>.conflict", Title: "Value conflicts with another field", Category: "conflict"},
+	{ID: "<field>.duplicate", Title: "Value is duplicated", Category: "duplicate"},
+	{ID: "<field>.reference", Title: "Reference does not match any object", Category: "reference"},
+	{ID: "<field>.implicit-type", Title: "Value changes type under YAML 1.1", Category: "implicit-type"},
+	{ID: "<field>.restricted", Title: "Violates the restricted profile", Severity: "warning", Category: "restricted", Categories: []string{categorySecurity}},
+	{ID: "<field>.secret", Title: "Secret given as a literal value", Severity: "warning", Category: "secret", Categories: []string{categorySecurity}},
+	{ID: "containers.volumeMounts.readOnly", Title: "configMap and secret mounts should be read-only", Severity: "warning", Category: "recommendation", Categories: []string{categoryBestPractice, categorySecurity}, Fixable: true},
+}
+
+// ruleCatalog — каталог: декларативные правила в порядке применения, затем встроенные проверки.
+func ruleCatalog() []catalogEntry {
+	var out []catalogEntry
+	for _, r := range rules {
+		e := catalogEntry{
+			ID:          r.ID,
+			Title:       r.Title,
+			Description: r.Description,
+			Severity:    r.Severity,
+			Category:    r.category(),
+			Links:       r.Links,
+			Categories:  r.Categories,
+		}
+		if e.Title == "" {
+			e.Title = r.Field
+		}
+		out = append(out, e)
+	}
+	out = append(out, builtinCatalog...)
+	for i := range out {
+		if url := docURL(out[i].ID); url != "" {
+			out[i].Links = append([]string{url}, out[i].Links...)
+		}
+		if out[i].Severity == "" {
+			out[i].Severity = "error"
+		}
+		if out[i].Categories == nil {
+			out[i].Categories = []string{categoryStructure}
+		}
+	}
+	return out
+}
+
+// category — категория декларативного правила по виду проверки.
+func (r *Rule) category() string {
+	switch {
+	case r.Required:
+		return "required"
+	case len(r.Enum) > 0:
+		return "enum"
+	case r.Pattern != "":
+		return "format"
+	}
+	return "range"
+}
+
+// runRules — подкоманда rules export: каталог правил для документации.
+func runRules(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("rules", flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	format := flags.String("format", "json", "output format: json")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s rules export [flags]\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "export" {
+		flags.Usage()
+		return 2
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *format != "json" {
+		fmt.Fprintf(w, "unknown format '%s'\n", *format)
+		return 2
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(w, configFile(*configPath), err)
+		return 1
+	}
+	// каталог включает наборы правил из конфига, как при проверке
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			printIOErr(w, config.RulesDir, err)
+			return 1
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			printIOErr(w, config.Rules, err)
+			return 1
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(struct {
+		Version string         `json:"version"`
+		Rules   []catalogEntry `json:"rules"`
+	}{rulesVersion(), ruleCatalog()})
+	return 0
+}
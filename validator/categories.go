@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Категории правил для --only-category и --skip-category
+const (
+	categoryStructure    = "structure"
+	categorySecurity     = "security"
+	categoryNaming       = "naming"
+	categoryResources    = "resources"
+	categoryBestPractice = "best-practice"
+)
+
+var ruleCategories = []string{categoryStructure, categorySecurity, categoryNaming, categoryResources, categoryBestPractice}
+
+// фрагменты полей, по которым встроенные проверки относятся к категории
+var (
+	securityFields  = []string{"securityContext", "capabilities", "hostPort", "hostNetwork", "hostPID", "hostIPC", "hostPath", "serviceAccount", "rules."}
+	resourcesFields = []string{"resources", "overhead", "limits", "requests", "priorityClassName"}
+)
+
+// findingCategories — категории находки: у декларативных правил — из их categories,
+// у встроенных проверок — по полю и виду сообщения.
+func findingCategories(e ValidationError) []string {
+	if e.Rule != "" {
+		for i := range rules {
+			if rules[i].ID == e.Rule && len(rules[i].Categories) > 0 {
+				return rules[i].Categories
+			}
+		}
+	}
+	id := RuleID(e)
+	field, _, _ := strings.Cut(e.Msg, " ")
+	switch {
+	case strings.HasSuffix(id, ".restricted") || strings.HasSuffix(id, ".secret") || containsAny(field, securityFields):
+		return []string{categorySecurity}
+	case containsAny(field, resourcesFields):
+		return []string{categoryResources}
+	case e.Severity == SeverityWarning:
+		return []string{categoryBestPractice}
+	case strings.HasSuffix(field, "name") && (strings.HasSuffix(id, ".format") || strings.HasSuffix(id, ".duplicate")):
+		return []string{categoryNaming}
+	}
+	return []string{categoryStructure}
+}
+
+func containsAny(s string, parts []string) bool {
+	for _, p := range parts {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCategories разбирает список категорий через запятую.
+func parseCategories(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var out []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if !contains(ruleCategories, c) {
+			return nil, fmt.Errorf("unknown category '%s'", c)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// filterCategories оставляет находки из only (если задан) и без категорий из skip.
+func filterCategories(errs []ValidationError, only, skip []string) []ValidationError {
+	if only == nil && skip == nil {
+		return errs
+	}
+	kept := errs[:0]
+	for _, e := range errs {
+		cats := findingCategories(e)
+		if (only == nil || intersects(cats, only)) && !intersects(cats, skip) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+func intersects(a, b []string) bool {
+	for _, v := range a {
+		if contains(b, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// источник claim'а пода: готовый ResourceClaim, шаблон или устаревший source
+var podClaimReqs = []requirement{exactlyOneOf("resourceClaimName", "resourceClaimTemplateName", "source")}
+
+func validateOverhead(n *yaml.Node, errs *[]ValidationError) {
+	if !expectType(n, yaml.MappingNode, "spec.overhead", errs) {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		validateQuantity(n.Content[i+1], "spec.overhead."+n.Content[i].Value, errs)
+	}
+}
+
+// validatePodResourceClaims проверяет spec.resourceClaims и возвращает имена claim'ов;
+// nil, если список задан некорректно и сверять с ним контейнеры нельзя.
+func validatePodResourceClaims(spec *yaml.Node, errs *[]ValidationError) map[string]bool {
+	const field = "spec.resourceClaims"
+	names := map[string]bool{}
+	_, claims := getMap(spec, "resourceClaims")
+	if claims == nil {
+		return names
+	}
+	if !expectType(claims, yaml.SequenceNode, field, errs) {
+		return nil
+	}
+	for _, c := range claims.Content {
+		if c.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(c, field+" must be array"))
+			continue
+		}
+		_, name := getMap(c, "name")
+		if name == nil {
+			*errs = append(*errs, errAt(c, field+".name is required"))
+		} else if expectString(name, field+".name", errs) {
+			switch {
+			case len(name.Value) > 63 || !dnsLabelRegex.MatchString(name.Value):
+				*errs = append(*errs, errAt(name, fmt.Sprintf("%s.name has invalid format '%s'", field, name.Value)))
+			case names[name.Value]:
+				*errs = append(*errs, errAt(name, fmt.Sprintf("%s.name '%s' is duplicated", field, name.Value)))
+			}
+			names[name.Value] = true
+		}
+		if !checkRequirements(c, field, podClaimReqs, errs) {
+			continue
+		}
+		for _, ref := range []string{"resourceClaimName", "resourceClaimTemplateName"} {
+			if _, v := getMap(c, ref); v != nil {
+				validateDNSName(v, field+"."+ref, errs)
+			}
+		}
+	}
+	return names
+}
+
+// validateContainerClaims сверяет resources.claims контейнера с claim'ами пода.
+func validateContainerClaims(c *yaml.Node, podClaims map[string]bool, errs *[]ValidationError) {
+	const field = "containers.resources.claims"
+	_, res := getMap(c, "resources")
+	_, claims := getMap(res, "claims")
+	if claims == nil || !expectType(claims, yaml.SequenceNode, field, errs) {
+		return
+	}
+	seen := map[string]bool{}
+	for _, cl := range claims.Content {
+		if cl.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(cl, field+" must be array"))
+			continue
+		}
+		_, name := getMap(cl, "name")
+		if name == nil {
+			*errs = append(*errs, errAt(cl, field+".name is required"))
+			continue
+		}
+		if !expectString(name, field+".name", errs) {
+			continue
+		}
+		// один claim может встречаться с разными request
+		key := name.Value
+		if _, req := getMap(cl, "request"); req != nil && expectString(req, field+".request", errs) {
+			key += "/" + req.Value
+		}
+		switch {
+		case seen[key]:
+			*errs = append(*errs, errAt(name, fmt.Sprintf("%s.name '%s' is duplicated", field, name.Value)))
+		case podClaims != nil && !podClaims[name.Value]:
+			*errs = append(*errs, errAt(name, fmt.Sprintf("%s.name '%s' does not match any spec.resourceClaims", field, name.Value)))
+		}
+		seen[key] = true
+	}
+}
@@ -0,0 +1,560 @@
+package validator
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Main разбирает аргументы командной строки, проверяет файлы и возвращает код выхода:
+// 0 — ошибок нет, 1 — найдены ошибки, 2 — неверный вызов.
+func Main(args []string) int {
+	for i, a := range args {
+		if a == persistentWorkerFlag {
+			startup := append(append([]string(nil), args[:i]...), args[i+1:]...)
+			return runWorker(startup, os.Stdin, os.Stdout)
+		}
+	}
+	return run(args, os.Stdout)
+}
+
+// run — Main с выводом в w; состояние предыдущего запуска (конфиг, правила) сбрасывается.
+func run(args []string, w io.Writer) int {
+	resetState()
+	args, err := expandFlagfiles(args)
+	if err != nil {
+		printIOErr(w, "flagfile", err)
+		return 2
+	}
+	if len(args) > 0 && args[0] == "impact" {
+		return runImpact(args[1:], w)
+	}
+	if len(args) > 0 && args[0] == "exceptions" {
+		return runExceptions(args[1:], w)
+	}
+	if len(args) > 0 && args[0] == "rules" {
+		return runRules(args[1:], w)
+	}
+	if len(args) > 0 && args[0] == "mutate" {
+		return runMutate(args[1:], w)
+	}
+	if len(args) > 0 && args[0] == "corpus" {
+		return runCorpus(args[1:], w)
+	}
+	if len(args) > 0 && args[0] == "terraform" {
+		return runTerraform(args[1:], w)
+	}
+	if len(args) > 0 && args[0] == "flux" {
+		return runFlux(args[1:], w)
+	}
+	if len(args) > 0 && args[0] == "argocd" {
+		return runArgoCD(args[1:], w)
+	}
+	if len(args) > 0 && args[0] == "krm" {
+		return runKRM(args[1:], stdin, w)
+	}
+	if len(args) > 0 && args[0] == "doctor" {
+		return runDoctor(args[1:], w)
+	}
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	profile := flags.String("profile", "", "validation profile: default or restricted (overrides config)")
+	checkImages := flags.Bool("check-images", false, "inspect image manifests in the registry")
+	detectSecrets := flags.Bool("detect-secrets", false, "warn about literal secrets in env values")
+	rulesPath := flags.String("rules", "", "rules file or oci:// bundle merged over the built-in rules (overrides config)")
+	rulesDir := flags.String("rules-dir", "", "directory of rule bundles merged over the built-in rules (overrides config)")
+	var schemaLocations stringList
+	flags.Var(&schemaLocations, "schema-location", "JSON schema URL or path template, may be repeated (added to config)")
+	contextPath := flags.String("context", "", "cluster context file to cross-check references against (overrides config)")
+	stdinBatch := flags.String("stdin-batch", "", "validate a stream of {filename, content} records from stdin: json or nul")
+	originMapPath := flags.String("origin-map", "", "JSON line map [{line, file, sourceLine}] attributing findings on generated input to source files")
+	fix := flags.Bool("fix", false, "apply available automatic fixes to the files")
+	fixFormatFlag := flags.String("fix-format", fixFormatFiles, "how --fix delivers fixes: files (rewrite the files) or patch (print RFC 6902 JSON Patch per document)")
+	groupBy := flags.String("group-by", "", "group findings: rule")
+	var outputs stringList
+	signReportKey := flags.String("sign-report", "", "ECDSA private key (PEM) to sign --output files with; writes <file>.sig in cosign sign-blob format")
+	flags.Var(&outputs, "output", "findings output format[=stdout|stderr|file]: text, json, sarif or intoto (attestation); may be repeated (default text)")
+	honorAnnotations := flags.Bool("honor-annotations", false, "apply validator.bigbrother.io/profile and disable-rules annotations of resources")
+	bufferSize := flags.Int("buffer-size", defaultBufferSize, "how many files of a directory run are read ahead; bounds memory use")
+	followSymlinks := flags.Bool("follow-symlinks", false, "descend into symlinked directories when validating a directory")
+	onlyCategory := flags.String("only-category", "", "report only findings of these categories (comma-separated: structure, security, naming, resources, best-practice)")
+	skipCategory := flags.String("skip-category", "", "hide findings of these categories (comma-separated)")
+	collapse := flags.Bool("collapse", false, "report a finding repeated in several containers once, with container indices")
+	quiet := flags.Bool("quiet", false, "print nothing, report only through the exit code")
+	errorsOnly := flags.Bool("errors-only", false, "hide warnings")
+	summaryOnly := flags.Bool("summary-only", false, "print only the totals")
+	maxWarnings := flags.Int("max-warnings", -1, "fail when there are more warnings than this (-1: no limit)")
+	showTimings := flags.Bool("timings", false, "print time spent in each rule; --output json then writes {results, timings}")
+	ratchetMode := flags.Bool("ratchet", false, "fail only if the number of findings of some rule grew compared to the ratchet state")
+	ratchetState := flags.String("ratchet-state", defaultRatchetState, "ratchet state file, rewritten when no rule grew")
+	printConfig := flags.Bool("print-config", false, "print the effective configuration merged with flags as YAML and exit")
+	probePreflight := flags.Bool("probe-preflight", false, "send the httpGet probe requests to a running instance at --base-url and report non-2xx/3xx responses")
+	baseURL := flags.String("base-url", "", "address of the running instance for --probe-preflight, e.g. http://localhost (a port here overrides the probe ports)")
+	perDocTimeoutFlag := flags.Duration("per-doc-timeout", 0, "report a document whose validation takes longer than this as an internal error (0: no limit)")
+	translateCompose := flags.Bool("translate-compose", false, "check docker-compose services as Pods and warn about settings that would violate the policy")
+	skipNonK8s := flags.Bool("skip-non-k8s", false, "skip files without apiVersion and kind (docker-compose, CI configs) instead of reporting them")
+	warnRBACWildcards := flags.Bool("warn-rbac-wildcards", false, "warn about wildcard verbs and resources in RBAC rules")
+	duplicateMinNodes := flags.Int("duplicate-min-nodes", 0, "warn about repeated subtrees of at least this many YAML nodes across containers and documents (0: off, overrides config)")
+	lintProbes := flags.Bool("lint-probes", false, "warn about identical liveness and readiness probes and liveness probes without readiness")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s [flags] <path/to/file.yaml|dir>...\n", flags.Name())
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 && *stdinBatch == "" && !*printConfig {
+		flags.Usage()
+		return 2
+	}
+	if *stdinBatch != "" && *stdinBatch != batchJSON && *stdinBatch != batchNUL {
+		fmt.Fprintf(w, "unknown stdin-batch format '%s'\n", *stdinBatch)
+		return 2
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(w, configFile(*configPath), err)
+		return 1
+	}
+	if *profile != "" {
+		config.Profile = *profile
+	}
+	if *checkImages {
+		config.CheckImages = true
+	}
+	if *detectSecrets {
+		config.DetectSecrets = true
+	}
+	if *honorAnnotations {
+		config.HonorAnnotations = true
+	}
+	if *warnRBACWildcards {
+		config.WarnRBACWildcards = true
+	}
+	if *lintProbes {
+		config.LintProbes = true
+	}
+	if *duplicateMinNodes < 0 {
+		fmt.Fprintf(w, "duplicate-min-nodes must not be negative\n")
+		return 2
+	}
+	if *duplicateMinNodes > 0 {
+		config.DuplicateMinNodes = *duplicateMinNodes
+	}
+	if *skipNonK8s {
+		config.SkipNonKubernetes = true
+	}
+	if *translateCompose {
+		config.TranslateCompose = true
+	}
+	if !contains(profiles, config.Profile) {
+		fmt.Fprintf(w, "unknown profile '%s'\n", config.Profile)
+		return 2
+	}
+	only, err := parseCategories(*onlyCategory)
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return 2
+	}
+	skip, err := parseCategories(*skipCategory)
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return 2
+	}
+	if *bufferSize < 1 {
+		fmt.Fprintf(w, "buffer-size must be positive\n")
+		return 2
+	}
+	if *perDocTimeoutFlag < 0 {
+		fmt.Fprintf(w, "per-doc-timeout must not be negative\n")
+		return 2
+	}
+	// замеры пишутся из проверки документа; брошенная по тайм-ауту горутина писала бы
+	// в них одновременно со следующим документом
+	if *perDocTimeoutFlag > 0 && *showTimings {
+		fmt.Fprintf(w, "per-doc-timeout cannot be combined with timings\n")
+		return 2
+	}
+	perDocTimeout = *perDocTimeoutFlag
+	if *probePreflight {
+		if *baseURL == "" {
+			fmt.Fprintf(w, "probe-preflight requires base-url\n")
+			return 2
+		}
+		u, err := parseProbeBaseURL(*baseURL)
+		if err != nil {
+			fmt.Fprintln(w, err)
+			return 2
+		}
+		probeBaseURL = u
+	}
+	if *fixFormatFlag != fixFormatFiles && *fixFormatFlag != fixFormatPatch {
+		fmt.Fprintf(w, "unknown fix-format '%s'\n", *fixFormatFlag)
+		return 2
+	}
+	fixFormat = *fixFormatFlag
+	if *originMapPath != "" {
+		if err := loadOriginMap(*originMapPath); err != nil {
+			printIOErr(w, *originMapPath, err)
+			return 2
+		}
+	}
+	if *groupBy != "" && *groupBy != "rule" {
+		fmt.Fprintf(w, "unknown group-by '%s'\n", *groupBy)
+		return 2
+	}
+	if *rulesDir != "" {
+		config.RulesDir = *rulesDir
+	}
+	if *rulesPath != "" {
+		config.Rules = *rulesPath
+	}
+	config.SchemaLocations = append(config.SchemaLocations, schemaLocations...)
+	if *contextPath != "" {
+		config.Context = *contextPath
+	}
+	if *printConfig {
+		if err := printEffectiveConfig(w); err != nil {
+			fmt.Fprintln(w, err)
+			return 1
+		}
+		return 0
+	}
+	if config.Context != "" {
+		if err := loadContext(config.Context); err != nil {
+			printIOErr(w, config.Context, err)
+			return 1
+		}
+	}
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			printIOErr(w, config.RulesDir, err)
+			return 1
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			printIOErr(w, config.Rules, err)
+			return 1
+		}
+	}
+	if *stdinBatch != "" {
+		failed, err := runBatch(*stdinBatch, stdin, w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "stdin: %v\n", err)
+			return 2
+		}
+		if failed {
+			return 1
+		}
+		return 0
+	}
+
+	summaryOut := w
+	if *quiet {
+		summaryOut = io.Discard
+	}
+	if *quiet || *summaryOnly {
+		w = io.Discard
+	}
+
+	if *showTimings {
+		ruleTimings = &timings{byRule: map[string]*ruleTiming{}}
+	}
+
+	// версии правил выводятся, только если встроенные чем-то дополнены
+	if len(ruleBundles) != 1 || ruleBundles[0] != builtinBundle {
+		fmt.Fprintf(w, "rules: %s\n", rulesVersion())
+	}
+
+	if len(outputs) == 0 {
+		outputs = stringList{outputText}
+	}
+	if *signReportKey != "" {
+		// подписываются только отчёты в файлах: у потока нет места для подписи
+		if !slices.ContainsFunc(outputs, toFile) {
+			fmt.Fprintln(w, "sign-report needs an --output written to a file")
+			return 2
+		}
+		key, err := parseSigningKey(*signReportKey)
+		if err != nil {
+			printIOErr(w, *signReportKey, err)
+			return 2
+		}
+		reportKey = key
+	}
+	var sinks []sink
+	for _, spec := range outputs {
+		s, err := openSink(spec, w, *groupBy)
+		if err != nil {
+			fmt.Fprintf(w, "output %s: %v\n", spec, err)
+			return 2
+		}
+		sinks = append(sinks, s)
+	}
+	// report передаёт находки файла всем получателям, fileErr — ошибку чтения
+	report := func(m *manifest) bool {
+		ok := true
+		for _, s := range sinks {
+			if err := s.manifest(m); err != nil {
+				printIOErr(w, m.file, err)
+				ok = false
+			}
+		}
+		return ok
+	}
+	fileErr := func(file string, err error) {
+		for _, s := range sinks {
+			if serr := s.fileError(file, err); serr != nil {
+				printIOErr(w, file, serr)
+			}
+		}
+	}
+
+	failed := false
+	var sum summary
+	// объекты всех файлов прогона: одинаковые kind/namespace/name в разных файлах — ошибка
+	index := resourceIndex{}
+	// finish доводит находки проверенного файла до вывода и сразу отдаёт их получателям
+	finish := func(m *manifest) {
+		index.add(m)
+		if !finishManifest(w, m, *fix) {
+			failed = true
+		}
+		m.errs = filterCategories(m.errs, only, skip)
+		// категории определяются по исходному сообщению, поэтому шаблоны — после фильтра
+		applyMessageTemplates(m)
+		sum.add(m)
+		if *collapse {
+			m.errs = collapseFindings(m)
+		}
+		if *errorsOnly {
+			m.errs = withoutWarnings(m.errs)
+		}
+		if !report(m) {
+			failed = true
+		}
+	}
+	if hasDir(flags.Args()) {
+		// каталоги читаются конвейером в два прохода: первый собирает для проверок между
+		// документами сводку всех файлов, второй проверяет файлы по одному и сразу выводит
+		// находки, так что память не растёт с размером каталога
+		files := listManifests(flags.Args(), *followSymlinks)
+		set := newDocumentSet()
+		for r := range readManifests(files, *bufferSize) {
+			if r.err == nil {
+				set.add(r.m)
+			}
+		}
+		for r := range readManifests(files, *bufferSize) {
+			if r.err != nil {
+				fileErr(r.file, r.err)
+				failed = true
+				continue
+			}
+			validateManifest(r.m)
+			set.validate(r.m)
+			finish(r.m)
+		}
+	} else {
+		// ошибки чтения выводятся на месте файла, как в режиме каталога
+		var files []scanResult
+		var manifests []*manifest
+		for _, file := range flags.Args() {
+			m, err := readManifest(file)
+			files = append(files, scanResult{file: file, m: m, err: err})
+			if err == nil {
+				validateManifest(m)
+				manifests = append(manifests, m)
+			}
+		}
+		validateDocumentSet(manifests)
+		for _, r := range files {
+			if r.err != nil {
+				fileErr(r.file, r.err)
+				failed = true
+				continue
+			}
+			finish(r.m)
+		}
+	}
+	for i, s := range sinks {
+		if err := s.close(); err != nil {
+			fmt.Fprintf(w, "output %s: %v\n", outputs[i], err)
+			failed = true
+		}
+	}
+	if *summaryOnly {
+		fmt.Fprintln(summaryOut, sum.String())
+	}
+	// в JSON замеры уже есть; текстом в тот же stdout они испортили бы документ
+	if ruleTimings != nil && !slices.ContainsFunc(outputs, jsonToStdout) {
+		ruleTimings.print(summaryOut)
+	}
+	// предупреждения, скрытые --errors-only, тоже расходуют бюджет
+	if *maxWarnings >= 0 && sum.warnings > *maxWarnings {
+		fmt.Fprintf(summaryOut, "too many warnings: %d (max %d)\n", sum.warnings, *maxWarnings)
+		failed = true
+	}
+	// под --ratchet ошибка — только рост числа находок, а не сами находки
+	findingsFailed := sum.errors > 0
+	if *ratchetMode {
+		increased, err := ratchet(summaryOut, *ratchetState, sum.rules)
+		if err != nil {
+			printIOErr(summaryOut, *ratchetState, err)
+			failed = true
+		}
+		findingsFailed = increased
+	}
+	if failed || findingsFailed {
+		return 1
+	}
+	return 0
+}
+
+// finishManifest доводит находки проверенного файла до вывода: отпечатки, исключения,
+// диапазоны и, при fix, исправления. false — файл не удалось переписать
+// или исправления не удалось вывести.
+func finishManifest(w io.Writer, m *manifest, fix bool) bool {
+	if m.skipped {
+		fmt.Fprintf(w, "%s: skipped, not a Kubernetes manifest\n", filepath.Base(m.file))
+	}
+	fingerprintFindings(m)
+	applyExceptions(m)
+	resolveRanges(m.src, m.errs)
+	// после исправлений: им нужны строки проверенного файла
+	defer applyOrigins(m)
+	if !fix {
+		return true
+	}
+	if fixFormat == fixFormatPatch {
+		if err := writeFixPatches(w, m); err != nil {
+			printIOErr(w, m.file, err)
+			return false
+		}
+		return true
+	}
+	n, err := fixManifest(m)
+	if err != nil {
+		printIOErr(w, m.file, err)
+		return false
+	}
+	if n > 0 {
+		fmt.Fprintf(w, "%s: fixed %d issue(s)\n", filepath.Base(m.file), n)
+	}
+	return true
+}
+
+// summary — итог прогона для --summary-only, --max-warnings и --ratchet
+type summary struct {
+	files, errors, warnings int
+	// число находок по идентификаторам правил
+	rules map[string]int
+}
+
+// withoutWarnings оставляет только ошибки (--errors-only).
+func withoutWarnings(errs []ValidationError) []ValidationError {
+	kept := errs[:0]
+	for _, e := range errs {
+		if e.Severity != SeverityWarning {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+func (s *summary) add(m *manifest) {
+	s.files++
+	if s.rules == nil {
+		s.rules = map[string]int{}
+	}
+	for _, e := range m.errs {
+		s.rules[RuleID(e)]++
+		if e.Severity == SeverityWarning {
+			s.warnings++
+		} else {
+			s.errors++
+		}
+	}
+}
+
+// String — "2 error(s), 1 warning(s) in 3 file(s)".
+func (s summary) String() string {
+	return fmt.Sprintf("%d error(s), %d warning(s) in %d file(s)", s.errors, s.warnings, s.files)
+}
+
+// printErrors выводит находки файла.
+func printErrors(w io.Writer, m *manifest) {
+	base := filepath.Base(m.file)
+	for _, e := range m.errs {
+		fmt.Fprintln(w, formatFinding(findingFile(base, e), e))
+	}
+}
+
+// findingFile — файл, к которому относится находка: исходный файл сгенерированного YAML или name.
+func findingFile(name string, e ValidationError) string {
+	if e.File != "" {
+		return e.File
+	}
+	return name
+}
+
+// formatFinding — строка вывода: "file:line msg", без строки — только сообщение.
+func formatFinding(name string, e ValidationError) string {
+	msg := e.Msg
+	if e.Hint != "" {
+		msg += " (hint: " + e.Hint + ")"
+	}
+	if url := docURL(RuleID(e)); url != "" {
+		msg += " (docs: " + url + ")"
+	}
+	if e.Severity == SeverityWarning {
+		msg = "warning: " + msg
+	}
+	if e.Line == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s:%d %s", name, e.Line, msg)
+}
+
+// stringList — повторяемый флаг
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// resetState возвращает конфигурацию и правила к встроенным значениям.
+func resetState() {
+	abandoned.Wait()
+	config = defaultConfig()
+	resetRules()
+	ruleTimings = nil
+	clusterContext = nil
+	schemas = map[string]*jsonSchema{}
+	originMap = nil
+	reportKey = nil
+	probeBaseURL = nil
+}
+
+func printIOErr(w io.Writer, file string, err error) {
+	base := filepath.Base(file)
+	var pErr *fs.PathError
+	var cErr *ConfigError
+	if errors.As(err, &cErr) && len(cErr.Findings) > 0 {
+		fmt.Fprintln(w, cErr)
+	} else if errors.As(err, &pErr) {
+		fmt.Fprintf(w, "%s: %v\n", base, pErr.Err)
+	} else {
+		fmt.Fprintf(w, "%s: %v\n", base, err)
+	}
+}
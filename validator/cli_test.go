@@ -0,0 +1,254 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// runCLI запускает run и возвращает код выхода и вывод.
+func runCLI(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	var out bytes.Buffer
+	code := run(args, &out)
+	return code, out.String()
+}
+
+const testPodTemplate = `apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+spec:
+  os: %s
+  containers:
+    - name: app
+      image: registry.bigbrother.io/app:v1.0.0
+      resources:
+        limits:
+          cpu: 1
+          memory: 1Gi
+        requests:
+          cpu: 1
+          memory: 1Gi
+`
+
+// testPod — Pod без находок, кроме тех, что даёт значение os.
+func testPod(name, os string) string {
+	return fmt.Sprintf(testPodTemplate, name, os)
+}
+
+// writeTestFile пишет файл в dir, создавая подкаталоги, и возвращает его путь.
+func writeTestFile(t *testing.T, dir, name, src string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// teamRulesConfig создаёт конфиг с пользовательским правилом team-label (обязательная
+// метка team) и возвращает путь к нему.
+func teamRulesConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	rules := writeTestFile(t, dir, "team.yaml", "name: team\nrules:\n  - id: team-label\n    scope: object\n    path: metadata.labels.team\n    required: true\n")
+	return writeTestFile(t, dir, "config.yml", "rules: "+rules+"\n")
+}
+
+// Каталог и тот же набор файлов списком дают одинаковый вывод: проверки между
+// документами видят все файлы каталога, а не только текущий.
+func TestDirectoryMatchesFileList(t *testing.T) {
+	tests := []struct {
+		dir      string
+		wantCode int
+		want     []string
+	}{
+		{dir: "crossfile", wantCode: 0},
+		{dir: "crossfile-service", wantCode: 1, want: []string{"spec.ports.targetPort 'metrics' does not match any container port name of Deployment/web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			dir := filepath.Join("testdata", tt.dir)
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var files []string
+			for _, e := range entries {
+				files = append(files, filepath.Join(dir, e.Name()))
+			}
+
+			dirCode, dirOut := runCLI(t, dir)
+			listCode, listOut := runCLI(t, files...)
+			if dirCode != listCode || dirOut != listOut {
+				t.Fatalf("directory mode differs from file list:\ndir (%d):\n%s\nlist (%d):\n%s", dirCode, dirOut, listCode, listOut)
+			}
+			if dirCode != tt.wantCode {
+				t.Errorf("exit code = %d, want %d\n%s", dirCode, tt.wantCode, dirOut)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(dirOut, w) {
+					t.Errorf("output lacks %q:\n%s", w, dirOut)
+				}
+			}
+			if strings.Contains(dirOut, "not found among validated documents") {
+				t.Errorf("cross-file reference not resolved:\n%s", dirOut)
+			}
+		})
+	}
+}
+
+// Каталог проверяется в два прохода, и находки каждого файла выводятся сразу; порядок
+// строк, ошибки чтения и проверки между файлами (в том числе повторы поддеревьев) те же,
+// что у списка файлов.
+func TestDirectoryStreamMatchesFileList(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"deploy.yaml", "sa.yaml", "hpa.yaml"} {
+		b, err := os.ReadFile(filepath.Join("testdata", "crossfile", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, dir, "b/"+name, string(b))
+		if name == "deploy.yaml" {
+			writeTestFile(t, dir, "a/copy/deploy.yaml", strings.Replace(string(b), "name: web\n", "name: web-copy\n", 1))
+		}
+	}
+	writeTestFile(t, dir, "a/bad.yaml", "kind: [\n")
+	// не Kubernetes: его документы в проверки между файлами не попадают
+	writeTestFile(t, dir, "a/values.yaml", "replicaCount: 1\nimage:\n  repository: web\n")
+	writeTestFile(t, dir, "a/pod.yaml", testPod("app", "Vyp"))
+
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, p)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// каталог выводится в порядке путей
+	slices.Sort(files)
+	for _, flags := range [][]string{nil, {"--duplicate-min-nodes", "6"}, {"--buffer-size", "1"}} {
+		t.Run(strings.Join(flags, " "), func(t *testing.T) {
+			dirCode, dirOut := runCLI(t, append(flags, dir)...)
+			listCode, listOut := runCLI(t, append(flags, files...)...)
+			if dirCode != listCode || dirOut != listOut {
+				t.Fatalf("directory mode differs from file list:\ndir (%d):\n%s\nlist (%d):\n%s", dirCode, dirOut, listCode, listOut)
+			}
+			if dirCode != 1 || !strings.Contains(dirOut, "bad.yaml: ") || !strings.Contains(dirOut, "pod.yaml:6 os has unsupported value 'Vyp'") {
+				t.Errorf("exit code %d, output:\n%s", dirCode, dirOut)
+			}
+			if len(flags) > 0 && flags[0] == "--duplicate-min-nodes" && !strings.Contains(dirOut, "duplicates") {
+				t.Errorf("no duplicate subtree across files:\n%s", dirOut)
+			}
+		})
+	}
+}
+
+// Вывод по каталогам отсортирован по путям, в каком бы порядке ни шли аргументы.
+// Выводится только имя файла, поэтому файлы различаются значением os.
+func TestDirectoryOutputSortedByPath(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "b/pod.yaml", testPod("b", "os-b"))
+	writeTestFile(t, dir, "a/pod.yaml", testPod("a", "os-a"))
+	writeTestFile(t, dir, "a/sub/pod.yaml", testPod("sub", "os-a-sub"))
+	writeTestFile(t, dir, "a/sub.yaml", testPod("sub-file", "os-a-sub-file"))
+	want := strings.Join([]string{
+		"pod.yaml:6 os has unsupported value 'os-a'",
+		"sub.yaml:6 os has unsupported value 'os-a-sub-file'",
+		"pod.yaml:6 os has unsupported value 'os-a-sub'",
+		"pod.yaml:6 os has unsupported value 'os-b'",
+	}, "\n") + "\n"
+	for _, args := range [][]string{{"b", "a"}, {"a/sub", "b", "a/pod.yaml", "a/sub.yaml"}} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			var paths []string
+			for _, a := range args {
+				paths = append(paths, filepath.Join(dir, a))
+			}
+			code, out := runCLI(t, paths...)
+			if code != 1 || out != want {
+				t.Errorf("exit code %d, output:\n%s\nwant:\n%s", code, out, want)
+			}
+		})
+	}
+}
+
+// Повторно определённый объект отмечается на месте повтора, среди остальных находок по
+// строкам; первое место названо так же, как файлы в выводе.
+func TestDuplicateResources(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a/first.yaml", testPod("web", "linux"))
+	writeTestFile(t, dir, "b/second.yaml", testPod("other", "Vyp")+"---\n"+testPod("web", "Vyp")+"---\n"+testPod("other", "linux"))
+	want := strings.Join([]string{
+		"second.yaml:6 os has unsupported value 'Vyp'",
+		"second.yaml:21 metadata.name 'web' is duplicated (hint: Pod/web is also defined at first.yaml:4)",
+		"second.yaml:23 os has unsupported value 'Vyp'",
+		"second.yaml:38 metadata.name 'other' is duplicated (hint: Pod/other is also defined at second.yaml:4)",
+	}, "\n") + "\n"
+	code, out := runCLI(t, dir)
+	if code != 1 || out != want {
+		t.Errorf("exit code %d, output:\n%s\nwant:\n%s", code, out, want)
+	}
+}
+
+// Ошибки чтения идут туда же, куда находки: в каждый --output, а не всегда в stdout.
+func TestFileErrorsFollowOutputs(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.yaml")
+	good := writeTestFile(t, dir, "good.yaml", testPod("app", "linux"))
+	// строка текстового вывода; в JSON то же сообщение идёт с полным путём
+	const textLine = "missing.yaml: no such file or directory"
+	textFile := filepath.Join(dir, "report.txt")
+	jsonFile := filepath.Join(dir, "report.json")
+	jsonError := `"error": "open ` + missing + `: no such file or directory"`
+
+	tests := []struct {
+		name    string
+		outputs []string
+		stdout  string
+		file    string
+		fileHas string
+	}{
+		{"default text", nil, textLine, "", ""},
+		{"text to a file", []string{"text=" + textFile}, "", textFile, textLine},
+		{"json to a file", []string{"json=" + jsonFile}, "", jsonFile, jsonError},
+		{"json to stdout", []string{"json"}, jsonError, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []string
+			for _, o := range tt.outputs {
+				args = append(args, "--output", o)
+			}
+			code, out := runCLI(t, append(args, missing, good)...)
+			if code != 1 {
+				t.Errorf("exit code %d, want 1", code)
+			}
+			if tt.stdout == "" && out != "" || !strings.Contains(out, tt.stdout) {
+				t.Errorf("stdout:\n%s\nwant %q", out, tt.stdout)
+			}
+			if tt.stdout != textLine && slices.Contains(strings.Split(out, "\n"), textLine) {
+				t.Errorf("read error printed as text to stdout:\n%s", out)
+			}
+			if tt.file != "" {
+				b, err := os.ReadFile(tt.file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(b), tt.fileHas) {
+					t.Errorf("%s:\n%s\nwant %q", filepath.Base(tt.file), b, tt.fileHas)
+				}
+			}
+		})
+	}
+}
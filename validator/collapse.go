@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// collapseFindings объединяет одинаковые находки разных контейнеров одного документа
+// в одну — первую — с перечнем индексов контейнеров (--collapse).
+func collapseFindings(m *manifest) []ValidationError {
+	type group struct {
+		first   int
+		indices []string
+	}
+	groups := map[string]*group{}
+	var keys []string
+	var out []ValidationError
+	for _, e := range m.errs {
+		p, ok := m.pathOf(e.node)
+		i := p.path.containerIndex()
+		if !ok || i < 0 {
+			out = append(out, e)
+			continue
+		}
+		idx := strconv.Itoa(p.path[i].Index)
+		key := fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%d", p.doc, p.path[:i], p.path[i+1:], e.Msg, e.Severity)
+		if g, ok := groups[key]; ok {
+			g.indices = append(g.indices, idx)
+			continue
+		}
+		groups[key] = &group{first: len(out), indices: []string{idx}}
+		keys = append(keys, key)
+		out = append(out, e)
+	}
+	for _, k := range keys {
+		if g := groups[k]; len(g.indices) > 1 {
+			out[g.first].Msg += fmt.Sprintf(" (containers %s)", strings.Join(g.indices, ", "))
+		}
+	}
+	return out
+}
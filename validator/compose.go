@@ -0,0 +1,251 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// restart сервиса compose → restartPolicy пода
+var composeRestartPolicies = map[string]string{
+	"no": "Never", "always": "Always", "on-failure": "OnFailure", "unless-stopped": "Always",
+}
+
+// размер в compose: 512m, 1g, 1gb
+var composeSizeRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([bkmg])b?$`)
+
+// validateCompose переводит сервисы docker-compose в псевдо-поды и проверяет их как поды
+// (--translate-compose). Узлы значений берутся из файла compose, поэтому находки указывают
+// на его строки; все находки — предупреждения с именем сервиса в подсказке.
+func validateCompose(m *manifest) {
+	_, services := getMap(m.docs[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, svc := services.Content[i], services.Content[i+1]
+		if svc.Kind != yaml.MappingNode {
+			continue
+		}
+		var found []ValidationError
+		validateDocument(composePod(name, svc), &found)
+		for _, e := range found {
+			if e.Line == 0 {
+				// у синтезированных узлов строки нет — находка относится к сервису
+				e.Line, e.Column = name.Line, name.Column
+			}
+			e.Severity = SeverityWarning
+			hint := fmt.Sprintf("translated from docker-compose service '%s'", name.Value)
+			if e.Hint != "" {
+				hint = e.Hint + "; " + hint
+			}
+			e.Hint = hint
+			e.Fix = nil
+			m.errs = append(m.errs, e)
+		}
+	}
+}
+
+// composePod строит Pod из сервиса compose; синтезированные узлы получают позицию at.
+func composePod(name, svc *yaml.Node) *yaml.Node {
+	at := func(v string) *yaml.Node { return synthScalar(name, v) }
+	c := synthMap(svc, "name", at(name.Value))
+	if _, image := getMap(svc, "image"); image != nil {
+		c.Content = append(c.Content, at("image"), image)
+	}
+	if _, ep := getMap(svc, "entrypoint"); ep != nil {
+		c.Content = append(c.Content, at("command"), composeCommand(ep))
+	}
+	if _, cmd := getMap(svc, "command"); cmd != nil {
+		c.Content = append(c.Content, at("args"), composeCommand(cmd))
+	}
+	if _, env := getMap(svc, "environment"); env != nil {
+		c.Content = append(c.Content, at("env"), composeEnv(env))
+	}
+	if _, ports := getMap(svc, "ports"); ports != nil && ports.Kind == yaml.SequenceNode {
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: ports.Line, Column: ports.Column}
+		for _, p := range ports.Content {
+			if port := composePort(p); port != nil {
+				list.Content = append(list.Content, port)
+			}
+		}
+		c.Content = append(c.Content, at("ports"), list)
+	}
+	if sc := composeSecurityContext(name, svc); len(sc.Content) > 0 {
+		c.Content = append(c.Content, at("securityContext"), sc)
+	}
+	if res := composeResources(name, svc); res != nil {
+		c.Content = append(c.Content, at("resources"), res)
+	}
+
+	spec := synthMap(svc, "containers", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{c}})
+	if _, restart := getMap(svc, "restart"); restart != nil && restart.Kind == yaml.ScalarNode {
+		policy, ok := composeRestartPolicies[restart.Value]
+		if !ok {
+			policy = restart.Value
+		}
+		spec.Content = append(spec.Content, at("restartPolicy"), synthScalar(restart, policy))
+	}
+	meta := synthMap(name, "name", at(name.Value), "namespace", at("default"))
+	if _, labels := getMap(svc, "labels"); labels != nil && labels.Kind == yaml.MappingNode {
+		meta.Content = append(meta.Content, at("labels"), labels)
+	}
+	return synthMap(name, "apiVersion", at("v1"), "kind", at("Pod"), "metadata", meta, "spec", spec)
+}
+
+// composeCommand: строку compose делит на слова, как shell без кавычек.
+func composeCommand(n *yaml.Node) *yaml.Node {
+	if n.Kind != yaml.ScalarNode {
+		return n
+	}
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: n.Line, Column: n.Column}
+	for _, f := range strings.Fields(n.Value) {
+		list.Content = append(list.Content, synthScalar(n, f))
+	}
+	return list
+}
+
+// composeEnv: environment как mapping или список KEY=VALUE.
+func composeEnv(n *yaml.Node) *yaml.Node {
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: n.Line, Column: n.Column}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			list.Content = append(list.Content, synthMap(k, "name", k, "value", synthScalar(v, v.Value)))
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			name, value, _ := strings.Cut(item.Value, "=")
+			list.Content = append(list.Content, synthMap(item, "name", synthScalar(item, name), "value", synthScalar(item, value)))
+		}
+	}
+	return list
+}
+
+// composePort: "8080:80", "127.0.0.1:8080:80/udp", "80" или {target, published, protocol}.
+func composePort(p *yaml.Node) *yaml.Node {
+	var target, published, protocol string
+	switch p.Kind {
+	case yaml.ScalarNode:
+		spec, proto, _ := strings.Cut(p.Value, "/")
+		parts := strings.Split(spec, ":")
+		target, protocol = parts[len(parts)-1], proto
+		if len(parts) > 1 {
+			published = parts[len(parts)-2]
+		}
+	case yaml.MappingNode:
+		target, published, protocol = scalarAt(p, "target"), scalarAt(p, "published"), scalarAt(p, "protocol")
+	default:
+		return nil
+	}
+	port := synthMap(p, "containerPort", synthInt(p, target))
+	if published != "" {
+		port.Content = append(port.Content, synthScalar(p, "hostPort"), synthInt(p, published))
+	}
+	if protocol != "" {
+		port.Content = append(port.Content, synthScalar(p, "protocol"), synthScalar(p, strings.ToUpper(protocol)))
+	}
+	return port
+}
+
+func composeSecurityContext(name, svc *yaml.Node) *yaml.Node {
+	sc := synthMap(name)
+	if _, v := getMap(svc, "privileged"); v != nil {
+		sc.Content = append(sc.Content, synthScalar(v, "privileged"), v)
+	}
+	if _, v := getMap(svc, "read_only"); v != nil {
+		sc.Content = append(sc.Content, synthScalar(v, "readOnlyRootFilesystem"), v)
+	}
+	if _, v := getMap(svc, "user"); v != nil && v.Kind == yaml.ScalarNode {
+		// user: "1000" или "1000:1000"; имя пользователя в поде не выразить
+		uid, gid, _ := strings.Cut(v.Value, ":")
+		if _, err := strconv.Atoi(uid); err == nil {
+			sc.Content = append(sc.Content, synthScalar(v, "runAsUser"), synthInt(v, uid))
+		}
+		if _, err := strconv.Atoi(gid); err == nil {
+			sc.Content = append(sc.Content, synthScalar(v, "runAsGroup"), synthInt(v, gid))
+		}
+	}
+	_, add := getMap(svc, "cap_add")
+	_, drop := getMap(svc, "cap_drop")
+	if add != nil || drop != nil {
+		caps := synthMap(name)
+		if add != nil {
+			caps.Content = append(caps.Content, synthScalar(add, "add"), add)
+		}
+		if drop != nil {
+			caps.Content = append(caps.Content, synthScalar(drop, "drop"), drop)
+		}
+		sc.Content = append(sc.Content, synthScalar(name, "capabilities"), caps)
+	}
+	return sc
+}
+
+// composeResources: deploy.resources.limits/reservations (cpus, memory) → limits/requests.
+func composeResources(name, svc *yaml.Node) *yaml.Node {
+	_, deploy := getMap(svc, "deploy")
+	_, res := getMap(deploy, "resources")
+	if res == nil {
+		return nil
+	}
+	out := synthMap(res)
+	for _, pair := range [][2]string{{"limits", "limits"}, {"reservations", "requests"}} {
+		_, src := getMap(res, pair[0])
+		if src == nil {
+			continue
+		}
+		dst := synthMap(src)
+		if _, cpus := getMap(src, "cpus"); cpus != nil {
+			dst.Content = append(dst.Content, synthScalar(cpus, "cpu"), synthInt(cpus, cpus.Value))
+		}
+		if _, mem := getMap(src, "memory"); mem != nil {
+			dst.Content = append(dst.Content, synthScalar(mem, "memory"), synthScalar(mem, composeSize(mem.Value)))
+		}
+		out.Content = append(out.Content, synthScalar(src, pair[1]), dst)
+	}
+	return out
+}
+
+// composeSize переводит размер compose (512m, 1gb) в количество Kubernetes (512Mi, 1Gi).
+func composeSize(v string) string {
+	m := composeSizeRegex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(v)))
+	if m == nil {
+		return v
+	}
+	suffix := map[string]string{"b": "", "k": "Ki", "m": "Mi", "g": "Gi"}[m[2]]
+	return m[1] + suffix
+}
+
+// synthScalar — строковый узел с позицией at.
+func synthScalar(at *yaml.Node, v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v, Line: at.Line, Column: at.Column}
+}
+
+// synthInt — числовой узел, если v — число, иначе строковый (его и отметит проверка типа).
+func synthInt(at *yaml.Node, v string) *yaml.Node {
+	n := synthScalar(at, v)
+	if _, err := strconv.Atoi(v); err == nil {
+		n.Tag = "!!int"
+	}
+	return n
+}
+
+// synthMap — mapping с позицией at из пар ключ/значение.
+func synthMap(at *yaml.Node, kv ...any) *yaml.Node {
+	m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: at.Line, Column: at.Column}
+	for i := 0; i+1 < len(kv); i += 2 {
+		var key *yaml.Node
+		switch k := kv[i].(type) {
+		case string:
+			key = synthScalar(at, k)
+		case *yaml.Node:
+			key = k
+		}
+		m.Content = append(m.Content, key, kv[i+1].(*yaml.Node))
+	}
+	return m
+}
@@ -0,0 +1,378 @@
+package validator
+
+import (
+	"crypto/ecdsa"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// файл конфигурации, который подхватывается из текущего каталога без --config
+const defaultConfigFile = ".validator.yaml"
+
+const (
+	profileDefault    = "default"
+	profileRestricted = "restricted"
+)
+
+var profiles = []string{profileDefault, profileRestricted}
+
+// JSON-схема .validator.yaml: конфиг проверяется тем же валидатором схем, что и манифесты
+//
+//go:embed config.schema.json
+var configSchemaJSON []byte
+
+// ConfigError — ошибка конфига File: нарушения схемы с номерами строк (Findings)
+// или неверное значение (Err)
+type ConfigError struct {
+	File     string
+	Findings []ValidationError
+	Err      error
+}
+
+func (e *ConfigError) Error() string {
+	if len(e.Findings) == 0 {
+		return e.Err.Error()
+	}
+	lines := make([]string, len(e.Findings))
+	for i, v := range e.Findings {
+		lines[i] = formatFinding(filepath.Base(e.File), v)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+type Config struct {
+	// Родительские конфиги: пути, http(s)-адреса, git::<repo>//<path>?ref=<ref>;
+	// этот конфиг накладывается поверх них (см. mergeConfigChain)
+	Extends []string `yaml:"extends,omitempty"`
+	// Профиль проверок: restricted добавляет предупреждения политики безопасности
+	Profile string `yaml:"profile"`
+	// Профили по пространствам имён документа; первое совпадение заменяет profile
+	NamespaceProfiles []NamespaceProfile `yaml:"namespaceProfiles"`
+	// Учитывать аннотации validator.bigbrother.io/* в манифестах (--honor-annotations)
+	HonorAnnotations bool `yaml:"honorAnnotations"`
+	// Нарушения restricted-профиля — ошибки, а не предупреждения
+	EnforceRestricted bool `yaml:"enforceRestricted"`
+	// Разрешённые securityContext.capabilities.add; без списка под restricted — только NET_BIND_SERVICE
+	CapabilitiesAllowlist []string `yaml:"capabilitiesAllowlist"`
+	// Небезопасные sysctl, разрешённые на узлах (--allowed-unsafe-sysctls kubelet'а)
+	AllowedUnsafeSysctls []string `yaml:"allowedUnsafeSysctls"`
+	// Пропускать файлы без apiVersion и kind (docker-compose, CI) вместо находки (--skip-non-k8s)
+	SkipNonKubernetes bool `yaml:"skipNonKubernetes"`
+	// Проверять сервисы docker-compose как поды, предупреждениями (--translate-compose)
+	TranslateCompose bool `yaml:"translateCompose"`
+	// Допустимые apiVersion по kind; список kind заменяет встроенную таблицу,
+	// "*" — версии, допустимые для kind вне таблицы (CRD, новые группы API)
+	APIVersions map[string][]string `yaml:"apiVersions"`
+	// Существующие в кластере PriorityClass; пустой список отключает проверку
+	PriorityClasses []string `yaml:"priorityClasses"`
+	// Разрешённые в наших кластерах RuntimeClass; пустой список отключает проверку
+	RuntimeClasses []string `yaml:"runtimeClasses"`
+	// Допустимые значения ports[].protocol; в кластерах без SCTP его можно убрать
+	Protocols []string `yaml:"protocols"`
+	// Политика архитектур образов для смешанного флота
+	ImagePolicy ImagePolicy `yaml:"imagePolicy"`
+	// Правила для подов с GPU
+	GPUPolicy GPUPolicy `yaml:"gpuPolicy"`
+	// Известные entrypoint'ы по префиксу образа: command, отличный от них, даёт предупреждение
+	ImageEntrypoints map[string][]string `yaml:"imageEntrypoints"`
+	// Сверять образы с манифестами в реестре (--check-images)
+	CheckImages bool `yaml:"checkImages"`
+	// Искать секреты в env открытым текстом (--detect-secrets)
+	DetectSecrets bool `yaml:"detectSecrets"`
+	// Регулярные выражения имён или значений env, которые не считаются секретами
+	SecretAllowlist []string `yaml:"secretAllowlist"`
+
+	// Метки, обязательные в metadata.labels каждого объекта
+	RequiredLabels []RequiredLabel `yaml:"requiredLabels"`
+
+	// Пути, присутствие которых в документе — ошибка: spec.hostNetwork,
+	// spec.containers[*].securityContext.privileged
+	ForbiddenFields []string `yaml:"forbiddenFields"`
+	// Ограничения значений по путям документа
+	ValuePolicies []ValuePolicy `yaml:"valuePolicies"`
+
+	// Адрес базы runbook'ов: к находке добавляется ссылка docsBaseURL + slug правила
+	DocsBaseURL string `yaml:"docsBaseURL"`
+	// Slug'и страниц по идентификаторам правил; по умолчанию doc правила или его идентификатор
+	DocSlugs map[string]string `yaml:"docSlugs"`
+	// Шаблоны сообщений по идентификаторам правил; поля .Value, .Path, .Field, .Message
+	Messages map[string]string `yaml:"messages"`
+	// Исключения: подавление находок правила с владельцем и сроком
+	Exceptions []Exception `yaml:"exceptions"`
+
+	// Предупреждать о '*' в verbs/resources правил RBAC (--warn-rbac-wildcards)
+	WarnRBACWildcards bool `yaml:"warnRBACWildcards"`
+	// Предупреждать об одинаковых liveness/readiness-пробах и liveness без readiness (--lint-probes)
+	LintProbes bool `yaml:"lintProbes"`
+	// Предупреждать о повторах поддеревьев от этого числа узлов (--duplicate-min-nodes); 0 — не искать
+	DuplicateMinNodes int `yaml:"duplicateMinNodes"`
+	// Предельные размеры пода и манифеста
+	Limits Limits `yaml:"limits"`
+	// Каталог наборов правил (--rules-dir) и отдельный файл правил (--rules), применяемые поверх встроенных
+	RulesDir string `yaml:"rulesDir"`
+	Rules    string `yaml:"rules"`
+	// Шаблоны адресов JSON-схем по kind (--schema-location), как у kubeconform
+	SchemaLocations []string `yaml:"schemaLocations"`
+	// Версия Kubernetes для {{ .NormalizedKubernetesVersion }}; по умолчанию master
+	KubernetesVersion string `yaml:"kubernetesVersion"`
+	// Список закреплённых sha256 скачиваемых схем и наборов правил (формат sha256sum)
+	ArtifactChecksums string `yaml:"artifactChecksums"`
+	// Не принимать скачанные схемы и наборы правил без закреплённой суммы
+	RequireChecksums bool `yaml:"requireChecksums"`
+	// Открытый ключ cosign (PEM): схемы по http(s) принимаются только с подписью <url>.sig
+	CosignPublicKey string `yaml:"cosignPublicKey"`
+	// Файл контекста кластера (--context): namespaces, priorityClasses, storageClasses, nodeLabels
+	Context string `yaml:"context"`
+	// Открытый ключ ed25519 (base64), которым подписаны наборы правил oci://
+	RulesPublicKey string `yaml:"rulesPublicKey"`
+
+	secretAllowlist []*regexp.Regexp
+	forbiddenFields [][]pathStep
+	checksums       map[string]string
+	messages        map[string]*template.Template
+	cosignKey       *ecdsa.PublicKey
+}
+
+// NamespaceProfile выбирает профиль для документов из пространств имён, подходящих
+// под шаблон namespace (prod-*) или регулярное выражение pattern.
+type NamespaceProfile struct {
+	Namespace string `yaml:"namespace"`
+	Pattern   string `yaml:"pattern"`
+	Profile   string `yaml:"profile"`
+
+	pattern *regexp.Regexp
+}
+
+func (p *NamespaceProfile) matches(ns string) bool {
+	if p.pattern != nil {
+		return p.pattern.MatchString(ns)
+	}
+	ok, _ := path.Match(p.Namespace, ns)
+	return ok
+}
+
+type RequiredLabel struct {
+	Name string `yaml:"name"`
+	// Регулярное выражение для значения; пустое — любое непустое значение
+	Pattern string `yaml:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// ValuePolicy ограничивает скалярные значения по пути: allow — только перечисленные,
+// deny — любые, кроме перечисленных.
+type ValuePolicy struct {
+	Path  string   `yaml:"path"`
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+
+	steps []pathStep
+}
+
+type ImagePolicy struct {
+	// Архитектуры узлов флота (amd64, arm64); пустой список отключает правило
+	Platforms []string `yaml:"platforms"`
+	// Префиксы образов, которые собираются под все архитектуры
+	MultiArchImages []string `yaml:"multiArchImages"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Profile:   profileDefault,
+		Protocols: []string{"TCP", "UDP", "SCTP"},
+		GPUPolicy: GPUPolicy{Resources: []string{"nvidia.com/gpu", "amd.com/gpu"}},
+		Limits: Limits{
+			MaxContainers:    20,
+			MaxEnvVars:       100,
+			MaxManifestBytes: 1 << 20,
+		},
+	}
+}
+
+var config = defaultConfig()
+
+func configFile(path string) string {
+	if path == "" {
+		return defaultConfigFile
+	}
+	return path
+}
+
+// loadConfig читает конфиг и делает его действующим. Ошибки чтения возвращаются как есть,
+// остальные — *ConfigError.
+func loadConfig(file string) error {
+	b, err := os.ReadFile(configFile(file))
+	if err != nil {
+		if file == "" && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if err := parseConfig(configFile(file), b); err != nil {
+		var cErr *ConfigError
+		if errors.As(err, &cErr) {
+			return err
+		}
+		return &ConfigError{File: configFile(file), Err: err}
+	}
+	return nil
+}
+
+func parseConfig(file string, b []byte) error {
+	c := defaultConfig()
+	err := mergeConfigChain(&c, file, b, nil)
+	if err != nil {
+		return err
+	}
+	for i, p := range c.Protocols {
+		c.Protocols[i] = strings.ToUpper(p)
+	}
+	for _, p := range c.SecretAllowlist {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("secretAllowlist: %w", err)
+		}
+		c.secretAllowlist = append(c.secretAllowlist, re)
+	}
+	for i := range c.NamespaceProfiles {
+		p := &c.NamespaceProfiles[i]
+		if (p.Namespace == "") == (p.Pattern == "") {
+			return fmt.Errorf("namespaceProfiles: exactly one of namespace, pattern is required")
+		}
+		if !contains(profiles, p.Profile) {
+			return fmt.Errorf("namespaceProfiles: profile has unsupported value '%s'", p.Profile)
+		}
+		if _, err := path.Match(p.Namespace, ""); err != nil {
+			return fmt.Errorf("namespaceProfiles %s: %w", p.Namespace, err)
+		}
+		if p.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("namespaceProfiles %s: %w", p.Pattern, err)
+		}
+		p.pattern = re
+	}
+	for i := range c.RequiredLabels {
+		l := &c.RequiredLabels[i]
+		if l.Name == "" {
+			return fmt.Errorf("requiredLabels: name is required")
+		}
+		if l.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(l.Pattern)
+		if err != nil {
+			return fmt.Errorf("requiredLabels %s: %w", l.Name, err)
+		}
+		l.pattern = re
+	}
+	for _, f := range c.ForbiddenFields {
+		steps, err := parsePath(f)
+		if err != nil {
+			return fmt.Errorf("forbiddenFields: %w", err)
+		}
+		c.forbiddenFields = append(c.forbiddenFields, steps)
+	}
+	for i := range c.ValuePolicies {
+		p := &c.ValuePolicies[i]
+		steps, err := parsePath(p.Path)
+		if err != nil {
+			return fmt.Errorf("valuePolicies: %w", err)
+		}
+		if p.Allow == nil && p.Deny == nil {
+			return fmt.Errorf("valuePolicies %s: allow or deny is required", p.Path)
+		}
+		p.steps = steps
+	}
+	if c.ArtifactChecksums != "" {
+		if c.checksums, err = parseChecksums(c.ArtifactChecksums); err != nil {
+			return fmt.Errorf("artifactChecksums: %w", err)
+		}
+	}
+	if c.CosignPublicKey != "" {
+		if c.cosignKey, err = parseCosignKey(c.CosignPublicKey); err != nil {
+			return fmt.Errorf("cosignPublicKey: %w", err)
+		}
+	}
+	if c.messages, err = parseMessageTemplates(c.Messages); err != nil {
+		return err
+	}
+	if err := parseExceptions(c.Exceptions); err != nil {
+		return err
+	}
+	config = c
+	return nil
+}
+
+// validateConfigSchema сверяет конфиг со схемой до разбора в Config, чтобы опечатки
+// в ключах и неверные типы указывались строкой, а не молча игнорировались.
+func validateConfigSchema(file string, b []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil || len(doc.Content) == 0 {
+		// синтаксические ошибки сообщит yaml.Unmarshal в Config
+		return nil
+	}
+	var root map[string]any
+	if err := json.Unmarshal(configSchemaJSON, &root); err != nil {
+		panic("config schema: " + err.Error())
+	}
+	var errs []ValidationError
+	(&jsonSchema{root: root}).validate(doc.Content[0], "", &errs)
+	if len(errs) > 0 {
+		return &ConfigError{File: file, Findings: errs}
+	}
+	return nil
+}
+
+// docProfile — профиль проверяемого документа (namespaceProfiles, аннотации). Хранится
+// отдельно от config и атомарно: горутина, брошенная по --per-doc-timeout, ещё читает
+// его, когда следующий документ уже выставил свой.
+var docProfile atomic.Pointer[string]
+
+// activeProfile — профиль текущего документа; вне проверки документа — общий из конфига.
+func activeProfile() string {
+	if p := docProfile.Load(); p != nil {
+		return *p
+	}
+	return config.Profile
+}
+
+// documentProfile — профиль документа по metadata.namespace; без совпадений — общий.
+func documentProfile(top *yaml.Node) string {
+	_, meta := getMap(top, "metadata")
+	_, ns := getMap(meta, "namespace")
+	if ns == nil || ns.Kind != yaml.ScalarNode {
+		return config.Profile
+	}
+	for i := range config.NamespaceProfiles {
+		if config.NamespaceProfiles[i].matches(ns.Value) {
+			return config.NamespaceProfiles[i].Profile
+		}
+	}
+	return config.Profile
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
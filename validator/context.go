@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ClusterContext — сведения о целевом кластере, с которыми сверяются ссылки манифестов.
+// Пустой список отключает соответствующую проверку.
+type ClusterContext struct {
+	Namespaces      []string `yaml:"namespaces"`
+	PriorityClasses []string `yaml:"priorityClasses"`
+	StorageClasses  []string `yaml:"storageClasses"`
+	// значения меток узлов по ключам; пустой список значений — любое значение
+	NodeLabels map[string][]string `yaml:"nodeLabels"`
+}
+
+// clusterContext — контекст из --context; nil, если не задан
+var clusterContext *ClusterContext
+
+func loadContext(file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var c ClusterContext
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return err
+	}
+	clusterContext = &c
+	return nil
+}
+
+// validateContextRefs сверяет namespace, priorityClassName, storageClassName
+// и nodeSelector документа с контекстом кластера.
+func validateContextRefs(top *yaml.Node, kind string, errs *[]ValidationError) {
+	ctx := clusterContext
+	_, meta := getMap(top, "metadata")
+	if _, ns := getMap(meta, "namespace"); ns != nil && ns.Kind == yaml.ScalarNode && len(ctx.Namespaces) > 0 && !contains(ctx.Namespaces, ns.Value) {
+		*errs = append(*errs, errAt(ns, fmt.Sprintf("metadata.namespace '%s' does not match any namespace in context", ns.Value)))
+	}
+	if kind == "StatefulSet" {
+		_, spec := getMap(top, "spec")
+		_, vcts := getMap(spec, "volumeClaimTemplates")
+		if vcts != nil && vcts.Kind == yaml.SequenceNode {
+			for _, t := range vcts.Content {
+				_, s := getMap(t, "spec")
+				validateContextStorageClass(s, "spec.volumeClaimTemplates.spec.storageClassName", errs)
+			}
+		}
+	}
+	pod := podSpecOf(top, kind)
+	if pod == nil {
+		return
+	}
+	if _, pc := getMap(pod, "priorityClassName"); pc != nil && pc.Kind == yaml.ScalarNode && len(ctx.PriorityClasses) > 0 &&
+		!contains(systemPriorityClasses, pc.Value) && !contains(ctx.PriorityClasses, pc.Value) {
+		*errs = append(*errs, errAt(pc, fmt.Sprintf("spec.priorityClassName '%s' does not match any priority class in context", pc.Value)))
+	}
+	if _, vols := getMap(pod, "volumes"); vols != nil && vols.Kind == yaml.SequenceNode {
+		for _, v := range vols.Content {
+			_, eph := getMap(v, "ephemeral")
+			_, tmpl := getMap(eph, "volumeClaimTemplate")
+			_, s := getMap(tmpl, "spec")
+			validateContextStorageClass(s, "spec.volumes.ephemeral.volumeClaimTemplate.spec.storageClassName", errs)
+		}
+	}
+	_, sel := getMap(pod, "nodeSelector")
+	if sel == nil || sel.Kind != yaml.MappingNode || len(ctx.NodeLabels) == 0 {
+		return
+	}
+	for i := 0; i+1 < len(sel.Content); i += 2 {
+		k, v := sel.Content[i], sel.Content[i+1]
+		values, known := ctx.NodeLabels[k.Value]
+		switch {
+		case !known:
+			*errs = append(*errs, errAt(k, fmt.Sprintf("spec.nodeSelector '%s' does not match any node label in context", k.Value)))
+		case len(values) > 0 && v.Kind == yaml.ScalarNode && !contains(values, v.Value):
+			e := errAt(v, fmt.Sprintf("spec.nodeSelector.%s '%s' does not match any node label in context", k.Value, v.Value))
+			sorted := append([]string(nil), values...)
+			sort.Strings(sorted)
+			e.Hint = fmt.Sprintf("known values: %v", sorted)
+			*errs = append(*errs, e)
+		}
+	}
+}
+
+func validateContextStorageClass(spec *yaml.Node, field string, errs *[]ValidationError) {
+	_, sc := getMap(spec, "storageClassName")
+	// пустой storageClassName — явный отказ от динамического выделения
+	if sc == nil || sc.Kind != yaml.ScalarNode || sc.Value == "" || len(clusterContext.StorageClasses) == 0 {
+		return
+	}
+	if !contains(clusterContext.StorageClasses, sc.Value) {
+		*errs = append(*errs, errAt(sc, fmt.Sprintf("%s '%s' does not match any storage class in context", field, sc.Value)))
+	}
+}
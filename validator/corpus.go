@@ -0,0 +1,150 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runCorpus — подкоманда corpus: каждый YAML-файл каталога с соседним файлом
+// <имя>.expected — тестовый случай. В .expected записан ожидаемый вывод валидатора
+// для файла, по находке в строке; расхождения выводятся, код возврата 1.
+func runCorpus(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("corpus", flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	update := flags.Bool("update", false, "rewrite .expected files with the current findings")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s corpus [flags] <dir>\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(w, configFile(*configPath), err)
+		return 1
+	}
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			printIOErr(w, config.RulesDir, err)
+			return 1
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			printIOErr(w, config.Rules, err)
+			return 1
+		}
+	}
+	files, err := manifestFiles(flags.Arg(0))
+	if err != nil {
+		printIOErr(w, flags.Arg(0), err)
+		return 1
+	}
+
+	cases, failedCases := 0, 0
+	for _, file := range files {
+		expectedFile := strings.TrimSuffix(file, filepath.Ext(file)) + ".expected"
+		want, err := readExpected(expectedFile)
+		if errors.Is(err, fs.ErrNotExist) && !*update {
+			// YAML без .expected — вспомогательный файл, не тестовый случай
+			continue
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			printIOErr(w, expectedFile, err)
+			return 1
+		}
+		cases++
+		got, err := corpusFindings(w, file)
+		if err != nil {
+			printIOErr(w, file, err)
+			failedCases++
+			continue
+		}
+		if *update {
+			if err := os.WriteFile(expectedFile, []byte(joinLines(got)), 0o644); err != nil {
+				printIOErr(w, expectedFile, err)
+				return 1
+			}
+			continue
+		}
+		missing, unexpected := diffFindings(got, want), diffFindings(want, got)
+		if len(missing) == 0 && len(unexpected) == 0 {
+			continue
+		}
+		failedCases++
+		fmt.Fprintf(w, "FAIL %s\n", file)
+		for _, f := range missing {
+			fmt.Fprintf(w, "  - %s\n", f)
+		}
+		for _, f := range unexpected {
+			fmt.Fprintf(w, "  + %s\n", f)
+		}
+	}
+	if *update {
+		fmt.Fprintf(w, "%d case(s) updated\n", cases)
+		return 0
+	}
+	fmt.Fprintf(w, "%d case(s), %d failed\n", cases, failedCases)
+	if failedCases > 0 {
+		return 1
+	}
+	return 0
+}
+
+// corpusFindings проверяет файл так же, как основной прогон, и возвращает строки вывода.
+func corpusFindings(w io.Writer, file string) ([]string, error) {
+	m, err := readManifest(file)
+	if err != nil {
+		return nil, err
+	}
+	validateManifest(m)
+	validateDocumentSet([]*manifest{m})
+	finishManifest(w, m, false)
+	applyMessageTemplates(m)
+	var buf bytes.Buffer
+	printErrors(&buf, m)
+	return readLines(&buf)
+}
+
+func readExpected(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readLines(f)
+}
+
+// readLines читает непустые строки; строки с # — комментарии.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
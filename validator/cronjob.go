@@ -0,0 +1,179 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func validateCronJobSpec(spec *yaml.Node, errs *[]ValidationError) {
+	// schedule (обязательное)
+	_, schedule := getMap(spec, "schedule")
+	if schedule == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.schedule is required"})
+	} else if expectString(schedule, "spec.schedule", errs) {
+		if pos, err := parseCron(schedule.Value); err != nil {
+			e := errAt(schedule, fmt.Sprintf("spec.schedule has invalid format '%s': %v at position %d", schedule.Value, err, pos+1))
+			if schedule.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+				pos++
+			}
+			e.Column += pos
+			*errs = append(*errs, e)
+		}
+	}
+
+	// timeZone (необязательное)
+	if _, tz := getMap(spec, "timeZone"); tz != nil && expectString(tz, "spec.timeZone", errs) {
+		if _, err := time.LoadLocation(tz.Value); err != nil || tz.Value == "" || tz.Value == "Local" {
+			*errs = append(*errs, errAt(tz, fmt.Sprintf("spec.timeZone has unsupported value '%s'", tz.Value)))
+		}
+	}
+
+	if _, cp := getMap(spec, "concurrencyPolicy"); cp != nil && expectString(cp, "spec.concurrencyPolicy", errs) {
+		if cp.Value != "Allow" && cp.Value != "Forbid" && cp.Value != "Replace" {
+			*errs = append(*errs, errAt(cp, fmt.Sprintf("spec.concurrencyPolicy has unsupported value '%s'", cp.Value)))
+		}
+	}
+	validateIntFields(spec, "spec.", errs, "startingDeadlineSeconds", "successfulJobsHistoryLimit", "failedJobsHistoryLimit")
+
+	// jobTemplate (обязательное)
+	_, jt := getMap(spec, "jobTemplate")
+	if jt == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.jobTemplate is required"})
+		return
+	}
+	if !expectType(jt, yaml.MappingNode, "spec.jobTemplate", errs) {
+		return
+	}
+	_, jobSpec := getMap(jt, "spec")
+	if jobSpec == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.jobTemplate.spec is required"})
+	} else if expectType(jobSpec, yaml.MappingNode, "spec.jobTemplate.spec", errs) {
+		validateJobSpec(jobSpec, errs)
+	}
+}
+
+var cronDescriptors = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // символические значения, names[i] соответствует min+i
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// parseCron разбирает выражение в формате, который принимает CronJob (пять полей или @-дескриптор),
+// и при ошибке возвращает смещение проблемного места от начала строки.
+func parseCron(expr string) (int, error) {
+	if strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		return 0, fmt.Errorf("time zone must be set via spec.timeZone")
+	}
+	if strings.HasPrefix(expr, "@") {
+		d := strings.TrimSpace(expr)
+		if strings.HasPrefix(d, "@every ") {
+			if dur, err := time.ParseDuration(strings.TrimSpace(d[len("@every "):])); err != nil || dur <= 0 {
+				return len("@every "), fmt.Errorf("invalid duration")
+			}
+			return 0, nil
+		}
+		if !contains(cronDescriptors, d) {
+			return 0, fmt.Errorf("unknown descriptor")
+		}
+		return 0, nil
+	}
+
+	var fields []string
+	var offsets []int
+	for i := 0; i < len(expr); {
+		if expr[i] == ' ' || expr[i] == '\t' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(expr) && expr[j] != ' ' && expr[j] != '\t' {
+			j++
+		}
+		fields = append(fields, expr[i:j])
+		offsets = append(offsets, i)
+		i = j
+	}
+	if len(fields) != len(cronFields) {
+		pos := len(expr)
+		if len(fields) > len(cronFields) {
+			pos = offsets[len(cronFields)]
+		}
+		return pos, fmt.Errorf("expected %d fields, found %d", len(cronFields), len(fields))
+	}
+	for i, f := range fields {
+		if pos, err := parseCronField(f, cronFields[i]); err != nil {
+			return offsets[i] + pos, fmt.Errorf("%s: %v", cronFields[i].name, err)
+		}
+	}
+	return 0, nil
+}
+
+func parseCronField(s string, f cronField) (int, error) {
+	off := 0
+	for _, part := range strings.Split(s, ",") {
+		if err := parseCronRange(part, f); err != nil {
+			return off, err
+		}
+		off += len(part) + 1
+	}
+	return 0, nil
+}
+
+func parseCronRange(s string, f cronField) error {
+	rng, step, hasStep := strings.Cut(s, "/")
+	if hasStep {
+		n, err := strconv.Atoi(step)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid step '%s'", step)
+		}
+	}
+	if rng == "*" || rng == "?" {
+		return nil
+	}
+	lo, hi, isRange := strings.Cut(rng, "-")
+	a, err := parseCronValue(lo, f)
+	if err != nil {
+		return err
+	}
+	if !isRange {
+		return nil
+	}
+	b, err := parseCronValue(hi, f)
+	if err != nil {
+		return err
+	}
+	if a > b {
+		return fmt.Errorf("range '%s' is reversed", rng)
+	}
+	return nil
+}
+
+func parseCronValue(s string, f cronField) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
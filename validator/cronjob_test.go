@@ -0,0 +1,78 @@
+package validator
+
+import "testing"
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+		wantPos int
+	}{
+		{expr: "*/5 * * * *"},
+		{expr: "0 9-17/2 * * MON-FRI"},
+		{expr: "0 0 1,15 JAN,jul *"},
+		{expr: "0 0 * * SUN-SAT"},
+		{expr: "0 0 * * 7"},
+		{expr: "? * * * *"},
+		{expr: "0\t0  * * *"},
+		{expr: "@hourly"},
+		{expr: "@every 1h30m"},
+		{"60 * * * *", "minute: value 60 out of range 0-59", 0},
+		{"0 24 * * *", "hour: value 24 out of range 0-23", 2},
+		{"0 0 0 * *", "day of month: value 0 out of range 1-31", 4},
+		{"0 0 1 13 *", "month: value 13 out of range 1-12", 6},
+		{"0 0 * * 8", "day of week: value 8 out of range 0-7", 8},
+		{"0 0 * FOO *", "month: invalid value 'FOO'", 6},
+		{"0 0 * * 1,FRI-MON", "day of week: range 'FRI-MON' is reversed", 10},
+		{"5-1/2 * * * *", "minute: range '5-1' is reversed", 0},
+		{"*/0 * * * *", "minute: invalid step '0'", 0},
+		{"0 */x * * *", "hour: invalid step 'x'", 2},
+		{"0 0 * *", "expected 5 fields, found 4", 7},
+		{"0 0 * * * *", "expected 5 fields, found 6", 10},
+		{"@fortnightly", "unknown descriptor", 0},
+		{"@every 0s", "invalid duration", 7},
+		{"CRON_TZ=UTC 0 * * * *", "time zone must be set via spec.timeZone", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			pos, err := parseCron(tt.expr)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("parseCron(%q) = %v, want no error", tt.expr, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr || pos != tt.wantPos {
+				t.Errorf("parseCron(%q) = %d, %v; want %d, %s", tt.expr, pos, err, tt.wantPos, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Колонка находки указывает на ошибочное поле выражения, с учётом кавычек.
+func TestCronScheduleColumn(t *testing.T) {
+	tests := []struct {
+		schedule string
+		wantCol  int
+	}{
+		{"0 24 * * *", 13},
+		{`"0 24 * * *"`, 14},
+		{"'0 0 * * 1,FRI-MON'", 22},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			m := parseTestManifest(t, "schedule: "+tt.schedule+"\njobTemplate:\n  spec: {}\n")
+			var errs []ValidationError
+			validateCronJobSpec(m.docs[0], &errs)
+			var got *ValidationError
+			for i, e := range errs {
+				if e.Line == 1 {
+					got = &errs[i]
+				}
+			}
+			if got == nil || got.Column != tt.wantCol {
+				t.Errorf("schedule finding %+v, want column %d; all: %v", got, tt.wantCol, errs)
+			}
+		})
+	}
+}
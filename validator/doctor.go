@@ -0,0 +1,255 @@
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// doctorReport — проблемы конфигурации, найденные doctor
+type doctorReport struct {
+	w                io.Writer
+	errors, warnings int
+}
+
+func (r *doctorReport) errorf(format string, args ...any) {
+	r.errors++
+	fmt.Fprintf(r.w, "error: "+format+"\n", args...)
+}
+
+func (r *doctorReport) warnf(format string, args ...any) {
+	r.warnings++
+	fmt.Fprintf(r.w, "warning: "+format+"\n", args...)
+}
+
+// runDoctor — подкоманда doctor: проверяет действующую конфигурацию (ссылки на правила,
+// доступность схем и реестра, противоречия, пользовательские правила) и выводит её.
+func runDoctor(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	offline := flags.Bool("offline", false, "skip checking that schema and registry endpoints are reachable")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s doctor [flags]\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	r := &doctorReport{w: w}
+	if err := loadConfig(*configPath); err != nil {
+		var cErr *ConfigError
+		if errors.As(err, &cErr) && len(cErr.Findings) > 0 {
+			for _, e := range cErr.Findings {
+				r.errorf("%s", formatFinding(filepath.Base(cErr.File), e))
+			}
+		} else {
+			r.errorf("%s: %v", configFile(*configPath), err)
+		}
+		fmt.Fprintf(w, "%d error(s), %d warning(s)\n", r.errors, r.warnings)
+		return 1
+	}
+	if config.Context != "" {
+		if err := loadContext(config.Context); err != nil {
+			r.errorf("context %s: %v", config.Context, err)
+		}
+	}
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			r.errorf("rulesDir %s: %v", config.RulesDir, err)
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			r.errorf("rules %s: %v", config.Rules, err)
+		}
+	}
+	checkRuleReferences(r)
+	checkOverrides(r)
+	if !*offline {
+		checkEndpoints(r)
+	}
+
+	fmt.Fprintf(w, "rules: %s\n", rulesVersion())
+	fmt.Fprintln(w, "effective configuration:")
+	if err := printEffectiveConfig(w); err != nil {
+		r.errorf("%v", err)
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s)\n", r.errors, r.warnings)
+	if r.errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printEffectiveConfig выводит конфигурацию после слияния с умолчаниями в виде YAML.
+func printEffectiveConfig(w io.Writer) error {
+	b, err := yaml.Marshal(&config)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// checkRuleReferences ищет идентификаторы правил в exceptions, messages и docSlugs,
+// которым не соответствует ни одно правило.
+func checkRuleReferences(r *doctorReport) {
+	for i, x := range config.Exceptions {
+		if x.Rule != "" && !knownRuleID(x.Rule) {
+			r.errorf("exceptions %d: rule '%s' does not match any rule", i+1, x.Rule)
+		}
+	}
+	for _, id := range sortedKeys(config.Messages) {
+		if !knownRuleID(id) {
+			r.errorf("messages: rule '%s' does not match any rule", id)
+		}
+	}
+	for _, id := range sortedKeys(config.DocSlugs) {
+		if !knownRuleID(id) {
+			r.errorf("docSlugs: rule '%s' does not match any rule", id)
+		}
+	}
+}
+
+// knownRuleID: id декларативного правила, встроенного из каталога или вида <field>.<category>.
+func knownRuleID(id string) bool {
+	for i := range rules {
+		if rules[i].ID == id {
+			return true
+		}
+	}
+	for _, e := range builtinCatalog {
+		if e.ID == id {
+			return true
+		}
+	}
+	if i := strings.LastIndex(id, "."); i > 0 {
+		for _, c := range messageCategories {
+			if id[i+1:] == c.category {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkOverrides сообщает о противоречивых настройках: правилах, переопределённых
+// или отключённых поздними наборами, профилях с одинаковым шаблоном и значениях,
+// которые valuePolicies одновременно разрешают и запрещают.
+func checkOverrides(r *doctorReport) {
+	defined := map[string]string{}
+	for _, b := range ruleBundles {
+		if b.Replace {
+			defined = map[string]string{}
+		}
+		for _, rule := range b.Rules {
+			prev, ok := defined[rule.ID]
+			switch {
+			case rule.Disabled && !ok:
+				r.warnf("rules %s: disabled rule '%s' does not match any rule", b.Name, rule.ID)
+			case rule.Disabled:
+				r.warnf("rules %s: rule '%s' from %s is disabled", b.Name, rule.ID, prev)
+			case ok:
+				r.warnf("rules %s: rule '%s' overrides the one from %s", b.Name, rule.ID, prev)
+			}
+			if rule.Disabled {
+				delete(defined, rule.ID)
+			} else {
+				defined[rule.ID] = b.Name
+			}
+		}
+	}
+
+	seen := map[string]string{}
+	for _, p := range config.NamespaceProfiles {
+		key := "namespace " + p.Namespace
+		if p.Pattern != "" {
+			key = "pattern " + p.Pattern
+		}
+		if prev, ok := seen[key]; ok && prev != p.Profile {
+			r.warnf("namespaceProfiles: %s conflicts with an earlier entry (profile %s, not %s)", key, prev, p.Profile)
+			continue
+		}
+		seen[key] = p.Profile
+	}
+
+	for _, p := range config.ValuePolicies {
+		for _, v := range p.Allow {
+			if contains(p.Deny, v) {
+				r.errorf("valuePolicies %s: value '%s' is both allowed and denied", p.Path, v)
+			}
+		}
+	}
+	for _, x := range config.Exceptions {
+		if x.expired() {
+			r.warnf("exceptions: %s %s", strings.TrimSpace(x.Rule+" "+x.Fingerprint), x.describe())
+		}
+	}
+}
+
+// checkEndpoints проверяет доступность адресов схем и реестра набора правил.
+// Ответ с любым HTTP-статусом считается доступностью: важно, что сервер отвечает.
+func checkEndpoints(r *doctorReport) {
+	data := schemaData("Pod", "v1")
+	for _, loc := range config.SchemaLocations {
+		tmpl, err := template.New("schema").Parse(loc)
+		if err != nil {
+			r.errorf("schemaLocations '%s': %v", loc, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			r.errorf("schemaLocations '%s': %v", loc, err)
+			continue
+		}
+		url := buf.String()
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			if _, err := os.Stat(filepath.Dir(url)); err != nil {
+				r.warnf("schemaLocations '%s': %v", loc, err)
+			}
+			continue
+		}
+		resp, err := schemaClient.Get(url)
+		if err != nil {
+			r.errorf("schemaLocations '%s' is unreachable: %v", loc, err)
+			continue
+		}
+		resp.Body.Close()
+	}
+	if strings.HasPrefix(config.Rules, ociRulesScheme) {
+		host, _, _, err := parseImageRef(strings.TrimPrefix(config.Rules, ociRulesScheme))
+		if err != nil {
+			r.errorf("rules %s: %v", config.Rules, err)
+			return
+		}
+		resp, err := registryGet("https://"+host+"/v2/", "")
+		if err != nil {
+			r.errorf("rules registry %s is unreachable: %v", host, err)
+			return
+		}
+		resp.Body.Close()
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,274 @@
+package validator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifest — один входной файл со всеми его YAML-документами
+type manifest struct {
+	file string
+	src  []byte
+	// корневые mapping'и документов
+	docs []*yaml.Node
+	errs []ValidationError
+	// файл не похож на манифест Kubernetes и пропущен (--skip-non-k8s)
+	skipped bool
+	// найденные пути узлов (pathOf) и узлы, для которых их искали
+	paths       map[*yaml.Node]nodePath
+	pathTargets map[*yaml.Node]struct{}
+}
+
+// имя, под которым выводятся находки YAML, прочитанного со stdin (аргумент "-")
+const stdinName = "stdin"
+
+// stdin читают аргумент "-", --stdin-batch и krm; воркер подменяет его на время запроса
+var stdin io.Reader = os.Stdin
+
+func readManifest(file string) (*manifest, error) {
+	if file == "-" {
+		// сгенерированный YAML на stdin: helm template ... | validator -
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+		return parseManifest(stdinName, b)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifest(file, b)
+}
+
+// parseManifest разбирает содержимое файла, полученное не с диска (stdin, редактор).
+func parseManifest(file string, b []byte) (*manifest, error) {
+	m := &manifest{file: file, src: b}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var root yaml.Node
+		if err := dec.Decode(&root); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, parseError(file, err)
+		}
+		// Находим корневой mapping; пустые документы между --- пропускаем
+		if root.Kind == yaml.DocumentNode && len(root.Content) > 0 && root.Content[0].Tag == "!!null" {
+			continue
+		}
+		var top *yaml.Node
+		switch root.Kind {
+		case yaml.DocumentNode:
+			if len(root.Content) > 0 && root.Content[0].Kind == yaml.MappingNode {
+				top = root.Content[0]
+			}
+		case yaml.MappingNode:
+			top = &root
+		}
+		if top == nil || top.Kind != yaml.MappingNode {
+			e := &ParseError{File: file, Err: ErrNotYAML}
+			if len(root.Content) > 0 {
+				e.Line, e.Col = root.Content[0].Line, root.Content[0].Column
+			}
+			return nil, e
+		}
+		m.docs = append(m.docs, top)
+	}
+	if len(m.docs) == 0 {
+		return nil, &ParseError{File: file, Err: ErrNotYAML}
+	}
+	return m, nil
+}
+
+// objectRef идентифицирует объект в проверяемом наборе документов
+type objectRef struct {
+	kind, namespace, name string
+}
+
+func refOf(top *yaml.Node) objectRef {
+	var ref objectRef
+	if _, k := getMap(top, "kind"); k != nil {
+		ref.kind = k.Value
+	}
+	if _, meta := getMap(top, "metadata"); meta != nil {
+		if _, n := getMap(meta, "name"); n != nil {
+			ref.name = n.Value
+		}
+		if _, ns := getMap(meta, "namespace"); ns != nil {
+			ref.namespace = ns.Value
+		}
+	}
+	return ref
+}
+
+// resourceIndex — объекты, уже встреченные за прогон, и где они определены
+type resourceIndex map[objectRef]resourceLocation
+
+type resourceLocation struct {
+	file string
+	line int
+}
+
+// add индексирует документы файла; объект, который уже определён в этом или другом
+// файле (типичная ошибка копирования в kustomize), даёт ошибку со ссылкой на первое место.
+// Файл в ссылке назван так же, как в выводе находок, а сама находка встаёт среди
+// остальных по номеру строки.
+func (idx resourceIndex) add(m *manifest) {
+	for i, top := range m.docs {
+		ref := refOf(top)
+		_, meta := getMap(top, "metadata")
+		_, name := getMap(meta, "name")
+		if ref.kind == "" || name == nil || name.Kind != yaml.ScalarNode || name.Value == "" {
+			continue
+		}
+		prev, ok := idx[ref]
+		if !ok {
+			idx[ref] = resourceLocation{file: m.file, line: name.Line}
+			continue
+		}
+		e := errAt(name, fmt.Sprintf("metadata.name '%s' is duplicated", name.Value))
+		e.Hint = fmt.Sprintf("%s is also defined at %s:%d", documentID(top, i), filepath.Base(prev.file), prev.line)
+		at := slices.IndexFunc(m.errs, func(f ValidationError) bool { return f.Line > e.Line })
+		if at < 0 {
+			at = len(m.errs)
+		}
+		m.errs = slices.Insert(m.errs, at, e)
+	}
+}
+
+// documentSet — то, что проверкам между документами нужно знать обо всех файлах прогона:
+// какие объекты есть, порты и метки подов, число повторов крупных поддеревьев. Узлов YAML
+// в нём нет, поэтому каталог проверяется в два прохода (add по всем файлам, затем validate
+// по каждому) и файлы не копятся в памяти до конца обхода.
+type documentSet struct {
+	objects map[objectRef]bool
+	pods    []podPorts
+	// повторы поддеревьев по всем файлам и первое вхождение каждого
+	subtrees map[[sha256.Size]byte]int
+	first    map[[sha256.Size]byte]occurrence
+}
+
+func newDocumentSet() *documentSet {
+	return &documentSet{
+		objects:  map[objectRef]bool{},
+		subtrees: map[[sha256.Size]byte]int{},
+		first:    map[[sha256.Size]byte]occurrence{},
+	}
+}
+
+// add запоминает объекты файла. Файл ещё может быть не проверен (первый проход по
+// каталогу), поэтому не-Kubernetes файлы, документы которых проверка отбросит, пропускаются.
+func (s *documentSet) add(m *manifest) {
+	if len(m.docs) == 0 {
+		return
+	}
+	if skip, _ := nonKubernetes(m); skip {
+		return
+	}
+	for i, top := range m.docs {
+		ref := refOf(top)
+		s.objects[ref] = true
+		if p, ok := podPortsOf(top, ref); ok {
+			p.id, p.file = documentID(top, i), m.file
+			s.pods = append(s.pods, p)
+		}
+	}
+	countDuplicates(m, s.subtrees)
+}
+
+// validate выполняет для файла проверки между документами; файлы передаются в том же
+// порядке, что и в add.
+func (s *documentSet) validate(m *manifest) {
+	for _, top := range m.docs {
+		ref := refOf(top)
+		if ref.kind == "HorizontalPodAutoscaler" {
+			validateHPATarget(top, ref.namespace, s.objects, &m.errs)
+		}
+		validateServiceAccountRefs(top, ref, s.objects, &m.errs)
+	}
+	// сервис без подходящих подов не проверяется: поды могут быть объявлены вне прогона
+	if len(s.pods) > 0 {
+		for _, top := range m.docs {
+			if ref := refOf(top); ref.kind == "Service" {
+				validateServiceTarget(top, ref.namespace, s.pods, &m.errs)
+			}
+		}
+	}
+	validateDuplicates(m, s.subtrees, s.first)
+}
+
+// validateDocumentSet выполняет проверки, которым нужны все документы сразу.
+func validateDocumentSet(manifests []*manifest) {
+	set := newDocumentSet()
+	for _, m := range manifests {
+		set.add(m)
+	}
+	for _, m := range manifests {
+		set.validate(m)
+	}
+}
+
+// podSpecOf возвращает спецификацию пода объекта: у Pod это spec, у контроллеров — шаблон.
+func podSpecOf(top *yaml.Node, kind string) *yaml.Node {
+	_, spec := getMap(podTemplateOf(top, kind), "spec")
+	if spec == nil || spec.Kind != yaml.MappingNode {
+		return nil
+	}
+	return spec
+}
+
+// podTemplateOf возвращает mapping с metadata и spec пода: у Pod это сам объект,
+// у контроллеров — template.
+func podTemplateOf(top *yaml.Node, kind string) *yaml.Node {
+	var path []string
+	switch kind {
+	case "Pod":
+		return top
+	case "Deployment", "StatefulSet", "Job":
+		path = []string{"spec", "template"}
+	case "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template"}
+	default:
+		return nil
+	}
+	tpl := top
+	for _, key := range path {
+		_, tpl = getMap(tpl, key)
+	}
+	if tpl == nil || tpl.Kind != yaml.MappingNode {
+		return nil
+	}
+	return tpl
+}
+
+func validateManifest(m *manifest) {
+	validateManifestSize(len(m.src), &m.errs)
+	if checkNonKubernetes(m) {
+		return
+	}
+	defer docProfile.Store(nil)
+	for _, top := range m.docs {
+		profile := documentProfile(top)
+		var disabled []string
+		if config.HonorAnnotations {
+			var override string
+			if override, disabled = documentOverrides(top, &m.errs); override != "" {
+				profile = override
+			}
+		}
+		docProfile.Store(&profile)
+		from := len(m.errs)
+		validateDocument(top, &m.errs)
+		m.errs = dropDisabled(m.errs, from, disabled)
+	}
+}
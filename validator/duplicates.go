@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+const duplicateSubtreeRule = "duplicate-subtree"
+
+// subtreeSum — хэш содержимого поддерева и число узлов в нём
+type subtreeSum struct {
+	hash [sha256.Size]byte
+	size int
+}
+
+// occurrence — первое вхождение поддерева, на которое ссылаются повторы
+type occurrence struct {
+	file string
+	line int
+}
+
+// countDuplicates считает крупные поддеревья файла (не меньше duplicateMinNodes узлов)
+// для validateDuplicates.
+func countDuplicates(m *manifest, counts map[[sha256.Size]byte]int) {
+	if config.DuplicateMinNodes <= 0 {
+		return
+	}
+	sums := map[*yaml.Node]subtreeSum{}
+	for _, top := range m.docs {
+		sumSubtree(top, sums)
+	}
+	for n, s := range sums {
+		if isDuplicateCandidate(n, s) {
+			counts[s.hash]++
+		}
+	}
+}
+
+// validateDuplicates ищет в контейнерах и документах файла поддеревья, которые по counts
+// встречаются в прогоне не раз, и советует вынести их в YAML-якорь или базу kustomize.
+// Первое вхождение запоминается в first и не отмечается. Ссылки на якорь (*name) повтором
+// не считаются.
+func validateDuplicates(m *manifest, counts map[[sha256.Size]byte]int, first map[[sha256.Size]byte]occurrence) {
+	if config.DuplicateMinNodes <= 0 {
+		return
+	}
+	sums := map[*yaml.Node]subtreeSum{}
+	for _, top := range m.docs {
+		sumSubtree(top, sums)
+	}
+	for _, top := range m.docs {
+		reportDuplicates(m, top, nil, sums, counts, first)
+	}
+}
+
+// sumSubtree вычисляет хэши всех поддеревьев n; стиль записи и позиции не учитываются.
+func sumSubtree(n *yaml.Node, sums map[*yaml.Node]subtreeSum) subtreeSum {
+	h := sha256.New()
+	h.Write([]byte{byte(n.Kind)})
+	h.Write([]byte(n.ShortTag() + "\x00" + n.Value + "\x00"))
+	size := 1
+	if n.Kind != yaml.AliasNode {
+		for _, c := range n.Content {
+			s := sumSubtree(c, sums)
+			h.Write(s.hash[:])
+			size += s.size
+		}
+	}
+	var s subtreeSum
+	copy(s.hash[:], h.Sum(nil))
+	s.size = size
+	sums[n] = s
+	return s
+}
+
+func isDuplicateCandidate(n *yaml.Node, s subtreeSum) bool {
+	return (n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode) && s.size >= config.DuplicateMinNodes
+}
+
+// reportDuplicates обходит документ сверху вниз: повторённое поддерево отмечается
+// целиком, вложенные в него повторы отдельно не выводятся.
+func reportDuplicates(m *manifest, n *yaml.Node, path FieldPath, sums map[*yaml.Node]subtreeSum, counts map[[sha256.Size]byte]int, first map[[sha256.Size]byte]occurrence) {
+	s := sums[n]
+	if isDuplicateCandidate(n, s) && counts[s.hash] > 1 {
+		at, seen := first[s.hash]
+		if !seen {
+			first[s.hash] = occurrence{file: m.file, line: n.Line}
+			return
+		}
+		field := path.String()
+		if field == "" {
+			field = "document"
+		}
+		e := warnAt(n, fmt.Sprintf("%s duplicates %s:%d (%d nodes)", field, at.file, at.line, s.size))
+		e.Rule = duplicateSubtreeRule
+		e.Hint = "define it once with a YAML anchor (&name, *name) or move it to a kustomize base"
+		m.errs = append(m.errs, e)
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			reportDuplicates(m, n.Content[i+1], path.Key(n.Content[i].Value), sums, counts, first)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			reportDuplicates(m, item, path.Index(i), sums, counts, first)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package validator
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// Ошибки библиотеки различаются через errors.Is/As: ошибки чтения — *fs.PathError и
+// прочие ошибки ввода-вывода как есть, разбора — *ParseError, конфига — *ConfigError.
+
+// ErrNotYAML — содержимое не является YAML-манифестом: в документе не mapping.
+var ErrNotYAML = errors.New("invalid YAML root (expected mapping)")
+
+// ParseError — файл File не разобран; Line и Col — позиция, если парсер её сообщил.
+type ParseError struct {
+	File string
+	Line int
+	Col  int
+	Err  error
+}
+
+// текст сообщает тот же, что и исходная ошибка: вывод CLI не меняется
+func (e *ParseError) Error() string { return e.Err.Error() }
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// строка в ошибках yaml.v3: "yaml: line 3: mapping values are not allowed in this context"
+var yamlErrorLineRegex = regexp.MustCompile(`^yaml: line (\d+):`)
+
+// parseError оборачивает ошибку yaml.v3, извлекая строку из текста.
+func parseError(file string, err error) *ParseError {
+	e := &ParseError{File: file, Err: err}
+	if m := yamlErrorLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
@@ -0,0 +1,159 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// формат даты expires в исключениях
+const expiresLayout = "2006-01-02"
+
+// текущее время для сверки сроков исключений
+var now = time.Now
+
+// Exception подавляет находки правила Rule в файлах, подходящих под шаблон File
+// (путь или имя файла; пустой — все файлы), либо одну находку по отпечатку Fingerprint.
+// После Expires исключение не действует.
+type Exception struct {
+	Rule        string `yaml:"rule"`
+	Fingerprint string `yaml:"fingerprint"`
+	File        string `yaml:"file"`
+	Owner       string `yaml:"owner"`
+	Expires     string `yaml:"expires"`
+	Reason      string `yaml:"reason"`
+
+	expires time.Time
+}
+
+func (x *Exception) expired() bool {
+	return !x.expires.IsZero() && !now().Before(x.expires.AddDate(0, 0, 1))
+}
+
+func (x *Exception) matches(file string, e ValidationError) bool {
+	if x.Fingerprint != "" && e.Fingerprint != x.Fingerprint {
+		return false
+	}
+	if x.Rule != "" && RuleID(e) != x.Rule {
+		return false
+	}
+	if x.File == "" {
+		return true
+	}
+	if ok, _ := path.Match(x.File, filepath.ToSlash(file)); ok {
+		return true
+	}
+	ok, _ := path.Match(x.File, filepath.Base(file))
+	return ok
+}
+
+// parseExceptions проверяет исключения конфига и разбирает сроки.
+func parseExceptions(list []Exception) error {
+	for i := range list {
+		x := &list[i]
+		if x.Rule == "" && x.Fingerprint == "" {
+			return fmt.Errorf("exceptions %d: rule or fingerprint is required", i+1)
+		}
+		if _, err := path.Match(x.File, ""); err != nil {
+			return fmt.Errorf("exceptions %d: %w", i+1, err)
+		}
+		if x.Expires == "" {
+			continue
+		}
+		t, err := time.ParseInLocation(expiresLayout, x.Expires, time.Local)
+		if err != nil {
+			return fmt.Errorf("exceptions %d: expires has invalid format '%s'", i+1, x.Expires)
+		}
+		x.expires = t
+	}
+	return nil
+}
+
+// applyExceptions убирает находки, подавленные действующими исключениями;
+// находкам с истёкшим исключением добавляется подсказка о нём.
+func applyExceptions(m *manifest) {
+	if len(config.Exceptions) == 0 {
+		return
+	}
+	var kept []ValidationError
+	for _, e := range m.errs {
+		suppressed := false
+		for i := range config.Exceptions {
+			x := &config.Exceptions[i]
+			if !x.matches(m.file, e) {
+				continue
+			}
+			if !x.expired() {
+				suppressed = true
+				break
+			}
+			if e.Hint == "" {
+				e.Hint = x.describe()
+			}
+		}
+		if !suppressed {
+			kept = append(kept, e)
+		}
+	}
+	m.errs = kept
+}
+
+// describe — "exception expired 2024-05-01, owner payments".
+func (x *Exception) describe() string {
+	s := "exception"
+	switch {
+	case x.expired():
+		s += " expired " + x.Expires
+	case x.Expires != "":
+		s += " expires " + x.Expires
+	}
+	if x.Owner != "" {
+		s += ", owner " + x.Owner
+	}
+	return s
+}
+
+// runExceptions — подкоманда exceptions list: исключения конфига со владельцами и сроками.
+func runExceptions(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("exceptions", flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s exceptions list [flags]\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "list" {
+		flags.Usage()
+		return 2
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(w, configFile(*configPath), err)
+		return 1
+	}
+	expired := 0
+	for i := range config.Exceptions {
+		x := &config.Exceptions[i]
+		file := x.File
+		if file == "" {
+			file = "*"
+		}
+		target := x.Rule
+		if x.Fingerprint != "" {
+			target = strings.TrimPrefix(x.Rule+" "+x.Fingerprint, " ")
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", target, file, x.describe())
+		if x.expired() {
+			expired++
+		}
+	}
+	fmt.Fprintf(w, "%d exception(s), %d expired\n", len(config.Exceptions), expired)
+	return 0
+}
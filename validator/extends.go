@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var extendsClient = &http.Client{Timeout: 30 * time.Second}
+
+// mergeConfigChain разбирает конфиг b из source в c поверх его родителей из extends:
+// сначала родители по порядку, затем сам конфиг. Скаляры и списки ребёнка заменяют
+// родительские, mapping'и (messages, docSlugs, apiVersions...) дополняются по ключам.
+// chain — конфиги, из которых пришли сюда; повтор в нём — цикл.
+func mergeConfigChain(c *Config, source string, b []byte, chain []string) error {
+	if err := validateConfigSchema(source, b); err != nil {
+		return err
+	}
+	var head struct {
+		Extends []string `yaml:"extends"`
+	}
+	if err := yaml.Unmarshal(b, &head); err != nil {
+		return err
+	}
+	id := source
+	if !isRemoteConfig(source) {
+		if abs, err := filepath.Abs(source); err == nil {
+			id = abs
+		}
+	}
+	chain = append(chain, id)
+	for _, ref := range head.Extends {
+		parent, data, err := fetchParentConfig(source, ref)
+		if err != nil {
+			return fmt.Errorf("%s: extends %s: %w", source, ref, err)
+		}
+		for _, seen := range chain {
+			if seen == parent {
+				return fmt.Errorf("extends cycle: %s -> %s", strings.Join(chain, " -> "), parent)
+			}
+		}
+		if err := mergeConfigChain(c, parent, data, chain); err != nil {
+			return err
+		}
+	}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return err
+	}
+	c.Extends = nil
+	return nil
+}
+
+// fetchParentConfig читает родительский конфиг: путь относительно ссылающегося файла,
+// http(s)-адрес или git::<repo>//<path>[?ref=<ref>] как в go-getter. Возвращает
+// нормализованный идентификатор источника (для поиска циклов) и содержимое.
+func fetchParentConfig(from, ref string) (string, []byte, error) {
+	switch {
+	case strings.HasPrefix(ref, "git::"):
+		return fetchGitConfig(strings.TrimPrefix(ref, "git::"))
+	case strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://"):
+		resp, err := extendsClient.Get(ref)
+		if err != nil {
+			return "", nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", nil, fmt.Errorf("server returned %s", resp.Status)
+		}
+		b, err := io.ReadAll(resp.Body)
+		return ref, b, err
+	}
+	p := ref
+	// локальные родители удалённого конфига неоднозначны: они берутся от рабочего каталога
+	if !filepath.IsAbs(p) && !isRemoteConfig(from) {
+		p = filepath.Join(filepath.Dir(from), p)
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", nil, err
+	}
+	b, err := os.ReadFile(abs)
+	return abs, b, err
+}
+
+func isRemoteConfig(source string) bool {
+	return strings.Contains(source, "://") || strings.HasPrefix(source, "git::")
+}
+
+// fetchGitConfig клонирует репозиторий (мелко, одну ветку) во временный каталог и читает файл.
+func fetchGitConfig(spec string) (string, []byte, error) {
+	spec, gitRef, _ := strings.Cut(spec, "?ref=")
+	// //path отделяет репозиторий от пути; первое // после схемы — часть адреса
+	schemeEnd := strings.Index(spec, "://") + len("://")
+	i := strings.Index(spec[schemeEnd:], "//")
+	if schemeEnd < len("://") || i < 0 {
+		return "", nil, fmt.Errorf("git source must be git::<repo>//<path>")
+	}
+	repo, file := spec[:schemeEnd+i], spec[schemeEnd+i+2:]
+	dir, err := os.MkdirTemp("", "validator-extends-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(dir)
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if gitRef != "" {
+		args = append(args, "--branch", gitRef)
+	}
+	args = append(args, repo, dir)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return "", nil, fmt.Errorf("git clone: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+	id := "git::" + repo + "//" + file
+	if gitRef != "" {
+		id += "?ref=" + gitRef
+	}
+	return id, b, err
+}
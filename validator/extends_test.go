@@ -0,0 +1,111 @@
+package validator
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Родители накладываются по порядку, ребёнок — последним; относительные пути в extends
+// считаются от файла, в котором записаны, а не от рабочего каталога.
+func TestConfigExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "org/base.yaml", "extends: [./defaults.yaml]\ndocsBaseURL: https://org.example.com/\nmessages:\n  pod-os: org {{ .Message }}\n")
+	writeTestFile(t, dir, "org/defaults.yaml", "schemaLocations: [default]\nmessages:\n  port-range: default {{ .Message }}\n")
+	writeTestFile(t, dir, "team/overrides.yaml", "messages:\n  pod-os: team {{ .Message }}\n")
+	configPath := writeTestFile(t, dir, "repo/config.yml", "extends: [../org/base.yaml, ../team/overrides.yaml]\nschemaLocations: [repo]\n")
+
+	// рабочий каталог не должен влиять на разрешение путей
+	t.Chdir(t.TempDir())
+	resetState()
+	t.Cleanup(resetState)
+	if err := loadConfig(configPath); err != nil {
+		t.Fatal(err)
+	}
+	if config.DocsBaseURL != "https://org.example.com/" {
+		t.Errorf("docsBaseURL = %q, want the one from org/base.yaml", config.DocsBaseURL)
+	}
+	if !reflect.DeepEqual(config.SchemaLocations, []string{"repo"}) {
+		t.Errorf("schemaLocations = %q, want the child's list", config.SchemaLocations)
+	}
+	wantMessages := map[string]string{"pod-os": "team {{ .Message }}", "port-range": "default {{ .Message }}"}
+	if !reflect.DeepEqual(config.Messages, wantMessages) {
+		t.Errorf("messages = %q, want %q", config.Messages, wantMessages)
+	}
+	if config.Extends != nil {
+		t.Errorf("extends = %q, want it cleared after merging", config.Extends)
+	}
+}
+
+func TestConfigExtendsErrors(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", "extends: [./sub/b.yaml]\n")
+	b := writeTestFile(t, dir, "sub/b.yaml", "extends: [../a.yaml]\n")
+	self := writeTestFile(t, dir, "self.yaml", "extends: [self.yaml]\n")
+	// ромб — не цикл: d доступен по двум путям
+	writeTestFile(t, dir, "diamond/b.yaml", "extends: [d.yaml]\n")
+	writeTestFile(t, dir, "diamond/c.yaml", "extends: [d.yaml]\n")
+	writeTestFile(t, dir, "diamond/d.yaml", "lintProbes: true\n")
+	diamond := writeTestFile(t, dir, "diamond/a.yaml", "extends: [b.yaml, c.yaml]\n")
+	missing := writeTestFile(t, dir, "missing.yaml", "extends: [./nope.yaml]\n")
+
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"cycle", a, "extends cycle: " + a + " -> " + b + " -> " + a},
+		{"self", self, "extends cycle: " + self + " -> " + self},
+		{"diamond", diamond, ""},
+		{"missing parent", missing, missing + ": extends ./nope.yaml: open " + filepath.Join(dir, "nope.yaml") + ": no such file or directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetState()
+			t.Cleanup(resetState)
+			err := loadConfig(tt.config)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("loadConfig: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("loadConfig error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// git::<repo>//<path>?ref=<ref> читает родителя из репозитория, как go-getter.
+func TestConfigExtendsGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	writeTestFile(t, repo, "policy/base-validator.yaml", "docsBaseURL: https://git.example.com/\n")
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "base"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	configPath := writeTestFile(t, t.TempDir(), "config.yml",
+		"extends: [\"git::file://"+filepath.ToSlash(repo)+"//policy/base-validator.yaml?ref=v1\"]\n")
+	resetState()
+	t.Cleanup(resetState)
+	if err := loadConfig(configPath); err != nil {
+		t.Fatal(err)
+	}
+	if config.DocsBaseURL != "https://git.example.com/" {
+		t.Errorf("docsBaseURL = %q, want the one from the git parent", config.DocsBaseURL)
+	}
+}
@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PathSegment — шаг пути к узлу: ключ mapping'а или индекс элемента списка (Index >= 0)
+type PathSegment struct {
+	Key   string
+	Index int
+}
+
+// FieldPath — путь к узлу в документе. Хранится сегментами и выводится только по
+// запросу: в виде для людей (String) или как JSON Pointer (Pointer).
+type FieldPath []PathSegment
+
+// Key возвращает путь, продолженный ключом k; исходный путь не меняется.
+func (p FieldPath) Key(k string) FieldPath {
+	return append(p[:len(p):len(p)], PathSegment{Key: k, Index: -1})
+}
+
+// Index возвращает путь, продолженный индексом элемента списка.
+func (p FieldPath) Index(i int) FieldPath {
+	return append(p[:len(p):len(p)], PathSegment{Index: i})
+}
+
+// String — spec.containers[0].image
+func (p FieldPath) String() string {
+	var b strings.Builder
+	for _, s := range p {
+		if s.Index >= 0 {
+			b.WriteString("[" + strconv.Itoa(s.Index) + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(s.Key)
+	}
+	return b.String()
+}
+
+// Pointer — JSON Pointer по RFC 6901 внутри документа: /spec/containers/0/image
+func (p FieldPath) Pointer() string {
+	var b strings.Builder
+	for _, s := range p {
+		b.WriteByte('/')
+		if s.Index >= 0 {
+			b.WriteString(strconv.Itoa(s.Index))
+			continue
+		}
+		b.WriteString(pointerEscaper.Replace(s.Key))
+	}
+	return b.String()
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// containerIndex — позиция индекса контейнера в пути (spec.containers[3] → 3-й сегмент)
+// или -1, если путь не ведёт внутрь контейнера.
+func (p FieldPath) containerIndex() int {
+	for i := 1; i < len(p); i++ {
+		if p[i].Index >= 0 && p[i-1].Index < 0 && contains(containerLists, p[i-1].Key) {
+			return i
+		}
+	}
+	return -1
+}
+
+var containerLists = []string{"containers", "initContainers", "ephemeralContainers"}
@@ -0,0 +1,85 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFieldPath(t *testing.T) {
+	tests := []struct {
+		path    FieldPath
+		str     string
+		pointer string
+	}{
+		{nil, "", ""},
+		{FieldPath{}.Key("spec").Key("containers").Index(0).Key("image"), "spec.containers[0].image", "/spec/containers/0/image"},
+		{FieldPath{}.Key("metadata").Key("annotations").Key("a/b~c"), "metadata.annotations.a/b~c", "/metadata/annotations/a~1b~0c"},
+		{FieldPath{}.Key("items").Index(2).Index(1), "items[2][1]", "/items/2/1"},
+	}
+	for _, tt := range tests {
+		if got := tt.path.String(); got != tt.str {
+			t.Errorf("String() = %q, want %q", got, tt.str)
+		}
+		if got := tt.path.Pointer(); got != tt.pointer {
+			t.Errorf("Pointer() = %q, want %q", got, tt.pointer)
+		}
+	}
+}
+
+// Путь продолжается без порчи исходного: сегменты не делят массив.
+func TestFieldPathKeyDoesNotAlias(t *testing.T) {
+	base := FieldPath{}.Key("spec").Key("containers")
+	a, b := base.Index(0), base.Index(1)
+	if a.String() != "spec.containers[0]" || b.String() != "spec.containers[1]" {
+		t.Errorf("got %s and %s", a, b)
+	}
+}
+
+func TestPathOf(t *testing.T) {
+	resetState()
+	m := parseTestManifest(t, "apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: api\nspec:\n  containers:\n    - name: a\n      image: bad image\n")
+	_, spec := getMap(m.docs[1], "spec")
+	_, conts := getMap(spec, "containers")
+	imageKey, image := getMap(conts.Content[0], "image")
+	// ключ mapping'а получает путь своего значения
+	for _, n := range []*yaml.Node{imageKey, image} {
+		p, ok := m.pathOf(n)
+		if !ok || p.path.String() != "spec.containers[0].image" || p.index != 1 || p.doc != "Pod/api" {
+			t.Errorf("pathOf(%s) = %+v, %v", n.Value, p, ok)
+		}
+	}
+	if p, ok := m.pathOf(m.docs[0]); !ok || p.path != nil || p.doc != "Pod/web" {
+		t.Errorf("document root: pathOf = %+v, %v", p, ok)
+	}
+}
+
+// benchmarkManifest — Deployment со множеством контейнеров, у каждого находки.
+func benchmarkManifest(tb testing.TB, containers int) *manifest {
+	var b strings.Builder
+	b.WriteString("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  selector:\n    matchLabels: {app: web}\n  template:\n    metadata:\n      labels: {app: web}\n    spec:\n      containers:\n")
+	for i := 0; i < containers; i++ {
+		fmt.Fprintf(&b, "        - name: c%d\n          image: bad image\n          env:\n            - {name: A, value: \"1\"}\n            - {name: B, value: \"2\"}\n          ports:\n            - containerPort: 70000\n", i)
+	}
+	m, err := parseManifest("bench.yaml", []byte(b.String()))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	validateManifest(m)
+	return m
+}
+
+// BenchmarkFindingPaths — отпечатки и --collapse одного файла: оба ищут пути узлов находок.
+func BenchmarkFindingPaths(b *testing.B) {
+	resetState()
+	base := benchmarkManifest(b, 200)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := &manifest{file: base.file, src: base.src, docs: base.docs, errs: append([]ValidationError(nil), base.errs...)}
+		fingerprintFindings(m)
+		collapseFindings(m)
+	}
+}
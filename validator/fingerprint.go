@@ -0,0 +1,145 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// nodePath — положение узла: документ (kind/namespace/name) и путь в нём.
+type nodePath struct {
+	doc  string
+	path FieldPath
+	// номер документа в файле, от 0
+	index int
+}
+
+// fingerprintFindings вычисляет отпечатки находок файла: хэш правила, документа, пути
+// к узлу и значения узла. В отличие от строки, отпечаток не меняется от правок выше по файлу.
+func fingerprintFindings(m *manifest) {
+	for i := range m.errs {
+		e := &m.errs[i]
+		parts := []string{RuleID(*e)}
+		if p, ok := m.pathOf(e.node); ok {
+			value := ""
+			if e.node.Kind == yaml.ScalarNode {
+				value = strings.TrimSpace(e.node.Value)
+			}
+			e.Path, e.Document = p.path, p.index
+			parts = append(parts, p.doc, p.path.String(), value)
+		} else {
+			// находки без узла ("apiVersion is required") различаются только сообщением
+			parts = append(parts, e.Msg)
+		}
+		sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+		e.Fingerprint = hex.EncodeToString(sum[:8])
+	}
+}
+
+// documentID — "Pod/default/web"; без имени — номер документа в файле.
+func documentID(top *yaml.Node, i int) string {
+	_, kind := getMap(top, "kind")
+	_, meta := getMap(top, "metadata")
+	_, ns := getMap(meta, "namespace")
+	_, name := getMap(meta, "name")
+	if name == nil || name.Kind != yaml.ScalarNode {
+		return "#" + strconv.Itoa(i)
+	}
+	id := name.Value
+	if ns != nil && ns.Kind == yaml.ScalarNode {
+		id = ns.Value + "/" + id
+	}
+	if kind != nil && kind.Kind == yaml.ScalarNode {
+		id = kind.Value + "/" + id
+	}
+	return id
+}
+
+// pathOf — положение узла n в документах файла. Пути ищутся одним обходом сразу для
+// всех узлов находок и запоминаются в манифесте, так что отпечатки, --collapse, шаблоны
+// сообщений и патчи исправлений обходят дерево один раз.
+func (m *manifest) pathOf(n *yaml.Node) (nodePath, bool) {
+	if n == nil {
+		return nodePath{}, false
+	}
+	if _, ok := m.pathTargets[n]; !ok {
+		m.findPaths(n)
+	}
+	p, ok := m.paths[n]
+	return p, ok
+}
+
+// findPaths обходит документы и запоминает пути узлов находок и узла extra.
+func (m *manifest) findPaths(extra *yaml.Node) {
+	if m.pathTargets == nil {
+		m.pathTargets = map[*yaml.Node]struct{}{}
+		m.paths = map[*yaml.Node]nodePath{}
+	}
+	m.pathTargets[extra] = struct{}{}
+	for _, e := range m.errs {
+		if e.node != nil {
+			m.pathTargets[e.node] = struct{}{}
+		}
+		if e.Fix != nil && e.Fix.node != nil {
+			m.pathTargets[e.Fix.node] = struct{}{}
+		}
+	}
+	w := pathWalker{targets: m.pathTargets, paths: m.paths}
+	for i, top := range m.docs {
+		w.top, w.index, w.doc = top, i, ""
+		w.walk(top)
+	}
+}
+
+// pathWalker ведёт путь общим стеком сегментов и копирует его только для искомых узлов:
+// остальные узлы дерева обходятся без выделений памяти.
+type pathWalker struct {
+	targets map[*yaml.Node]struct{}
+	paths   map[*yaml.Node]nodePath
+	top     *yaml.Node
+	index   int
+	// documentID документа; вычисляется при первом найденном в нём узле
+	doc   string
+	stack FieldPath
+}
+
+// record запоминает путь n; ключ mapping'а получает путь своего значения.
+func (w *pathWalker) record(n *yaml.Node) {
+	if _, ok := w.targets[n]; !ok {
+		return
+	}
+	if _, done := w.paths[n]; done {
+		return
+	}
+	if w.doc == "" {
+		w.doc = documentID(w.top, w.index)
+	}
+	var path FieldPath
+	if len(w.stack) > 0 {
+		path = slices.Clone(w.stack)
+	}
+	w.paths[n] = nodePath{doc: w.doc, path: path, index: w.index}
+}
+
+func (w *pathWalker) walk(n *yaml.Node) {
+	w.record(n)
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			w.stack = append(w.stack, PathSegment{Key: n.Content[i].Value, Index: -1})
+			w.record(n.Content[i])
+			w.walk(n.Content[i+1])
+			w.stack = w.stack[:len(w.stack)-1]
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			w.stack = append(w.stack, PathSegment{Index: i})
+			w.walk(item)
+			w.stack = w.stack[:len(w.stack)-1]
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fix — автоисправление находки: текст Text заменяет диапазон [Line:Column, EndLine:EndColumn);
+// при совпадающих началах и концах это вставка. Позиции вычисляются в resolveRanges.
+type Fix struct {
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+	Text      string
+
+	node *yaml.Node
+	// вставка сразу после узла вместо замены
+	after bool
+	// для патча: добавляемый ключ (пустой — замена значения узла) и значение в виде YAML
+	key, value string
+}
+
+// Форматы --fix-format
+const (
+	// исправления применяются к файлам
+	fixFormatFiles = "files"
+	// файлы не меняются, исправления выводятся как JSON Patch (RFC 6902)
+	fixFormatPatch = "patch"
+)
+
+// fixFormat — как --fix выдаёт исправления
+var fixFormat = fixFormatFiles
+
+// replaceFix заменяет значение узла.
+func replaceFix(n *yaml.Node, text string) *Fix {
+	return &Fix{Text: text, node: n, value: text}
+}
+
+// appendKeyFix добавляет "key: value" последней строкой блочного mapping'а;
+// для flow-mapping исправление не предлагается.
+func appendKeyFix(m *yaml.Node, key, value string) *Fix {
+	if m.Kind != yaml.MappingNode || m.Style&yaml.FlowStyle != 0 || len(m.Content) == 0 {
+		return nil
+	}
+	indent := strings.Repeat(" ", m.Content[0].Column-1)
+	return &Fix{Text: "\n" + indent + key + ": " + value, node: m, after: true, key: key, value: value}
+}
+
+// resolveFix вычисляет позиции исправления по исходному тексту.
+func resolveFix(lines []string, f *Fix) {
+	endLine, endCol := nodeEnd(lines, f.node)
+	if f.after {
+		f.Line, f.Column = endLine, endCol
+	} else {
+		f.Line, f.Column = f.node.Line, f.node.Column
+	}
+	f.EndLine, f.EndColumn = endLine, endCol
+}
+
+// applyFixes применяет исправления к src; пересекающиеся пропускаются.
+// Возвращает новый текст и индексы находок, исправления которых применены.
+func applyFixes(src []byte, errs []ValidationError) ([]byte, map[int]bool) {
+	lines := strings.Split(string(src), "\n")
+	// смещение в байтах по строке и колонке в символах
+	offset := func(line, col int) int {
+		off := 0
+		for i := 0; i < line-1 && i < len(lines); i++ {
+			off += len(lines[i]) + 1
+		}
+		if line-1 < len(lines) {
+			l := []rune(lines[line-1])
+			if col-1 > len(l) {
+				col = len(l) + 1
+			}
+			off += len(string(l[:col-1]))
+		}
+		return off
+	}
+	type edit struct {
+		err        int
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for i, e := range errs {
+		if f := e.Fix; f != nil && f.Line > 0 {
+			edits = append(edits, edit{i, offset(f.Line, f.Column), offset(f.EndLine, f.EndColumn), f.Text})
+		}
+	}
+	// с конца, чтобы смещения более ранних правок не сдвигались
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := string(src)
+	applied, limit := map[int]bool{}, len(out)
+	for _, ed := range edits {
+		if ed.end > limit {
+			continue
+		}
+		out = out[:ed.start] + ed.text + out[ed.end:]
+		applied[ed.err] = true
+		limit = ed.start
+	}
+	return []byte(out), applied
+}
+
+// fixManifest переписывает файл с исправлениями и убирает исправленные находки.
+func fixManifest(m *manifest) (int, error) {
+	fixed, applied := applyFixes(m.src, m.errs)
+	if len(applied) == 0 {
+		return 0, nil
+	}
+	info, err := os.Stat(m.file)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(m.file, fixed, info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	var rest []ValidationError
+	for i, e := range m.errs {
+		if !applied[i] {
+			rest = append(rest, e)
+		}
+	}
+	m.errs = rest
+	return len(applied), nil
+}
+
+// jsonPatchOp — операция JSON Patch (RFC 6902)
+type jsonPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// documentPatch — исправления одного документа файла; JSON Patch применяется к документу,
+// а не к файлу из нескольких документов
+type documentPatch struct {
+	File     string        `json:"file"`
+	Document int           `json:"document"`
+	Patch    []jsonPatchOp `json:"patch"`
+}
+
+// fixPatches собирает исправления находок в JSON Patch по документам. Второе исправление
+// того же пути пропускается, как пересекающаяся правка в applyFixes.
+func fixPatches(m *manifest) ([]documentPatch, error) {
+	byDoc := map[int]*documentPatch{}
+	var docs []int
+	seen := map[string]bool{}
+	for _, e := range m.errs {
+		f := e.Fix
+		if f == nil {
+			continue
+		}
+		at, ok := m.pathOf(f.node)
+		if !ok {
+			continue
+		}
+		op := jsonPatchOp{Op: "replace", Path: at.path.Pointer()}
+		if f.key != "" {
+			op = jsonPatchOp{Op: "add", Path: at.path.Key(f.key).Pointer()}
+		}
+		if err := yaml.Unmarshal([]byte(f.value), &op.Value); err != nil {
+			return nil, fmt.Errorf("fix for %s: %w", op.Path, err)
+		}
+		key := fmt.Sprint(at.index, op.Path)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		p := byDoc[at.index]
+		if p == nil {
+			p = &documentPatch{File: m.file, Document: at.index}
+			byDoc[at.index] = p
+			docs = append(docs, at.index)
+		}
+		p.Patch = append(p.Patch, op)
+	}
+	sort.Ints(docs)
+	patches := make([]documentPatch, 0, len(docs))
+	for _, d := range docs {
+		patches = append(patches, *byDoc[d])
+	}
+	return patches, nil
+}
+
+// writeFixPatches выводит исправления файла строками JSON, по документу на строку.
+func writeFixPatches(w io.Writer, m *manifest) error {
+	patches, err := fixPatches(m)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, p := range patches {
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
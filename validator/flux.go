@@ -0,0 +1,355 @@
+package validator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// переменная подстановки postBuild: ${var}, ${var:=default}, ${var:-default}
+var fluxVarRegex = regexp.MustCompile(`\$\{([_a-zA-Z][_a-zA-Z0-9]*)(?::[=-]([^}]*))?\}`)
+
+// аннотация Flux, отключающая подстановку в ресурсе
+const fluxSubstituteAnnotation = "kustomize.toolkit.fluxcd.io/substitute: disabled"
+
+var fluxClient = &http.Client{Timeout: 30 * time.Second}
+
+// fluxStatus — тело уведомления о результате проверки (--notify)
+type fluxStatus struct {
+	Artifact string        `json:"artifact"`
+	Path     string        `json:"path"`
+	Status   string        `json:"status"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+	Results  []batchResult `json:"results"`
+}
+
+// runFlux — подкоманда flux: задание после сборки Kustomization Flux. Скачивает артефакт
+// источника (tar.gz source-controller), подставляет переменные postBuild.substitute, проверяет
+// манифесты каталога --path и отправляет итог вебхуком провайдера уведомлений.
+func runFlux(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("flux", flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	artifact := flags.String("artifact", "", "artifact URL or path to a .tar.gz produced by source-controller")
+	dir := flags.String("path", ".", "path inside the artifact, as in Kustomization spec.path")
+	notify := flags.String("notify", "", "webhook URL of a generic notification provider to post the result to")
+	var vars stringList
+	flags.Var(&vars, "var", "postBuild substitution NAME=VALUE (repeatable)")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s flux --artifact <url|file> [flags]\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *artifact == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	substitute := map[string]string{}
+	for _, v := range vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			fmt.Fprintf(w, "var '%s' must be NAME=VALUE\n", v)
+			return 2
+		}
+		substitute[name] = value
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(w, configFile(*configPath), err)
+		return 1
+	}
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			printIOErr(w, config.RulesDir, err)
+			return 1
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			printIOErr(w, config.Rules, err)
+			return 1
+		}
+	}
+	archived, err := fluxArtifactFiles(*artifact)
+	if err != nil {
+		printIOErr(w, *artifact, err)
+		return 1
+	}
+	files, err := fluxResources(archived, path.Clean(strings.TrimPrefix(*dir, "./")))
+	if err != nil {
+		printIOErr(w, *artifact, err)
+		return 1
+	}
+
+	var manifests []*manifest
+	status := fluxStatus{Artifact: *artifact, Path: *dir, Results: []batchResult{}}
+	for _, f := range files {
+		m, err := parseManifest(f.name, fluxSubstitute(f.data, substitute))
+		if err != nil {
+			fmt.Fprintf(w, "%s: %v\n", f.name, err)
+			status.Errors++
+			status.Results = append(status.Results, batchResult{Filename: f.name, Error: err.Error(), Findings: []jsonFinding{}})
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	for _, m := range manifests {
+		validateManifest(m)
+	}
+	validateDocumentSet(manifests)
+	index := resourceIndex{}
+	for _, m := range manifests {
+		index.add(m)
+		finishManifest(w, m, false)
+		applyMessageTemplates(m)
+		// файлы артефакта выводятся с путём внутри него: имена вроде deploy.yaml повторяются в оверлеях
+		for _, e := range m.errs {
+			fmt.Fprintln(w, formatFinding(findingFile(m.file, e), e))
+		}
+		res := batchResult{Filename: m.file, Findings: []jsonFinding{}}
+		for _, e := range m.errs {
+			if e.Severity == SeverityWarning {
+				status.Warnings++
+			} else {
+				status.Errors++
+			}
+			res.Findings = append(res.Findings, toJSONFinding(e))
+		}
+		status.Results = append(status.Results, res)
+	}
+	status.Status = "success"
+	if status.Errors > 0 {
+		status.Status = "failure"
+	}
+	if *notify != "" {
+		if err := postFluxStatus(*notify, status); err != nil {
+			printIOErr(w, *notify, err)
+			return 1
+		}
+	}
+	if status.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+type artifactFile struct {
+	name string
+	data []byte
+}
+
+// fluxArtifactFiles читает *.yaml/*.yml и файлы Kustomization архива-артефакта, по порядку в архиве.
+func fluxArtifactFiles(src string) ([]artifactFile, error) {
+	var r io.Reader
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		resp, err := fluxClient.Get(src)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("artifact server returned %s", resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(src)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	var files []artifactFile
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if ext := path.Ext(name); ext != ".yaml" && ext != ".yml" && !isKustomization(name) {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, artifactFile{name: name, data: data})
+	}
+	return files, nil
+}
+
+// имена файла Kustomization в порядке, в котором их ищет kustomize
+var kustomizationNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+func isKustomization(name string) bool {
+	return slices.Contains(kustomizationNames, path.Base(name))
+}
+
+// fluxResources отбирает файлы, которые kustomize-controller соберёт из каталога dir. Каталог с
+// kustomization.yaml даёт файлы из её resources (вложенные каталоги — рекурсивно, удалённые
+// ресурсы пропускаются); каталог без неё — все манифесты, как kustomization, которую
+// controller генерирует сам. Файлы Kustomization не проверяются, patches не применяются.
+func fluxResources(files []artifactFile, dir string) ([]artifactFile, error) {
+	byName := map[string]artifactFile{}
+	for _, f := range files {
+		byName[f.name] = f
+	}
+	// каталоги с kustomization и их файлы Kustomization
+	kustomizations := map[string]string{}
+	for _, k := range slices.Backward(kustomizationNames) {
+		for _, f := range files {
+			if path.Base(f.name) == k {
+				kustomizations[path.Dir(f.name)] = f.name
+			}
+		}
+	}
+	inDir := func(name, dir string) bool {
+		return dir == "." || strings.HasPrefix(name, dir+"/")
+	}
+	var out []artifactFile
+	seen := map[string]bool{}
+	var collect func(dir string) error
+	collect = func(dir string) error {
+		if seen[dir] {
+			return nil
+		}
+		seen[dir] = true
+		k, ok := kustomizations[dir]
+		if !ok {
+			for _, f := range files {
+				if !inDir(f.name, dir) || isKustomization(f.name) {
+					continue
+				}
+				// вложенный каталог со своей kustomization собирается ею
+				if sub := nearestKustomization(f.name, dir, kustomizations); sub != "" {
+					if err := collect(sub); err != nil {
+						return err
+					}
+					continue
+				}
+				if !seen[f.name] {
+					seen[f.name] = true
+					out = append(out, f)
+				}
+			}
+			return nil
+		}
+		var kust struct {
+			Resources []string `yaml:"resources"`
+			Bases     []string `yaml:"bases"`
+		}
+		if err := yaml.Unmarshal(byName[k].data, &kust); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		for _, r := range append(kust.Resources, kust.Bases...) {
+			name := path.Join(dir, r)
+			if f, ok := byName[name]; ok && !isKustomization(name) {
+				if !seen[name] {
+					seen[name] = true
+					out = append(out, f)
+				}
+				continue
+			}
+			if slices.ContainsFunc(files, func(f artifactFile) bool { return inDir(f.name, name) }) {
+				if err := collect(name); err != nil {
+					return err
+				}
+				continue
+			}
+			if strings.Contains(r, "://") || strings.HasPrefix(r, "github.com/") || strings.HasPrefix(r, "git@") {
+				continue
+			}
+			return fmt.Errorf("%s: resource '%s' not found in artifact", k, r)
+		}
+		return nil
+	}
+	if err := collect(dir); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// nearestKustomization возвращает ближайший к dir каталог между dir и файлом name, в котором
+// есть kustomization, или "".
+func nearestKustomization(name, dir string, kustomizations map[string]string) string {
+	rel := strings.TrimPrefix(name, dir+"/")
+	if dir == "." {
+		rel = name
+	}
+	parts := strings.Split(path.Dir(rel), "/")
+	sub := dir
+	for _, p := range parts {
+		if p == "." {
+			break
+		}
+		sub = path.Join(sub, p)
+		if _, ok := kustomizations[sub]; ok {
+			return sub
+		}
+	}
+	return ""
+}
+
+// fluxSubstitute подставляет переменные, как kustomize-controller после сборки: неизвестная
+// переменная без значения по умолчанию становится пустой строкой. Документы с аннотацией
+// kustomize.toolkit.fluxcd.io/substitute: disabled остаются как есть.
+func fluxSubstitute(src []byte, vars map[string]string) []byte {
+	docs := bytes.Split(src, []byte("\n---"))
+	for i, doc := range docs {
+		if bytes.Contains(doc, []byte(fluxSubstituteAnnotation)) {
+			continue
+		}
+		docs[i] = fluxVarRegex.ReplaceAllFunc(doc, func(m []byte) []byte {
+			sub := fluxVarRegex.FindSubmatch(m)
+			if v, ok := vars[string(sub[1])]; ok {
+				return []byte(v)
+			}
+			return sub[2]
+		})
+	}
+	return bytes.Join(docs, []byte("\n---"))
+}
+
+func postFluxStatus(url string, status fluxStatus) error {
+	b, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	resp, err := fluxClient.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notification provider returned %s", resp.Status)
+	}
+	return nil
+}
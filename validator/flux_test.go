@@ -0,0 +1,127 @@
+package validator
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeArtifact собирает tar.gz артефакта source-controller из пар имя, содержимое.
+func writeArtifact(t *testing.T, files ...string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "artifact.tar.gz")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for i := 0; i < len(files); i += 2 {
+		data := []byte(files[i+1])
+		if err := tw.WriteHeader(&tar.Header{Name: files[i], Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestFluxKustomization(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		path     string
+		wantCode int
+		want     []string
+		notWant  []string
+	}{
+		{
+			name: "no kustomization validates every manifest",
+			files: []string{
+				"apps/good.yaml", testPod("good", "linux"),
+				"apps/bad.yaml", testPod("bad", "Vyp"),
+				"other/bad.yaml", testPod("other", "Vyp"),
+			},
+			path:     "./apps",
+			wantCode: 1,
+			want:     []string{"apps/bad.yaml:6 os has unsupported value 'Vyp'"},
+			notWant:  []string{"other/bad.yaml"},
+		},
+		{
+			name: "kustomization resources only",
+			files: []string{
+				"base/kustomization.yaml", "resources:\n  - pod.yaml\n",
+				"base/pod.yaml", testPod("base", "linux"),
+				"overlay/kustomization.yaml", "resources:\n  - ../base\n  - extra.yaml\n  - https://example.com/remote.yaml\npatches:\n  - path: patch.yaml\n",
+				"overlay/extra.yaml", testPod("extra", "windows"),
+				"overlay/patch.yaml", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: base\n",
+				"overlay/unused.yaml", testPod("unused", "Vyp"),
+			},
+			path:     "overlay",
+			wantCode: 0,
+			notWant:  []string{"kustomization", "patch.yaml", "unused.yaml"},
+		},
+		{
+			name: "nested kustomization in generated one",
+			files: []string{
+				"clusters/app/Kustomization", "resources:\n  - pod.yaml\n",
+				"clusters/app/pod.yaml", testPod("app", "Vyp"),
+				"clusters/app/unused.yaml", "kind: [",
+			},
+			path:     "clusters",
+			wantCode: 1,
+			want:     []string{"clusters/app/pod.yaml:6 os has unsupported value 'Vyp'"},
+			notWant:  []string{"Kustomization", "unused.yaml"},
+		},
+		{
+			name: "missing resource",
+			files: []string{
+				"kustomization.yml", "resources:\n  - missing.yaml\n",
+			},
+			path:     ".",
+			wantCode: 1,
+			want:     []string{"kustomization.yml: resource 'missing.yaml' not found in artifact"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact := writeArtifact(t, tt.files...)
+			code, out := runCLI(t, "flux", "--artifact", artifact, "--path", tt.path)
+			if code != tt.wantCode {
+				t.Errorf("code = %d, want %d; output:\n%s", code, tt.wantCode, out)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(out, s) {
+					t.Errorf("output mentions %q:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+// Правила из rules: конфига применяются и в подкоманде flux.
+func TestFluxLoadsConfigRules(t *testing.T) {
+	configPath := teamRulesConfig(t)
+	artifact := writeArtifact(t, "pod.yaml", testPod("app", "linux"))
+	code, out := runCLI(t, "flux", "--config", configPath, "--artifact", artifact)
+	if code != 1 || !strings.Contains(out, "pod.yaml:") || !strings.Contains(out, "team") {
+		t.Errorf("code = %d, want 1 with a team finding; output:\n%s", code, out)
+	}
+}
@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GPU выделяются только целиком
+var wholeNumberRegex = regexp.MustCompile(`^[0-9]+$`)
+
+type GPUPolicy struct {
+	// Расширенные ресурсы GPU; пустой список отключает правила
+	Resources []string `yaml:"resources"`
+	// Метка (и ключ taint'а) узлов GPU-пула: под с GPU должен выбирать её через nodeSelector
+	// или допускать taint; пустое значение отключает проверку
+	NodePoolLabel string `yaml:"nodePoolLabel"`
+}
+
+// validateGPU проверяет запросы GPU контейнеров пода: целое число, requests == limits,
+// и привязку пода к GPU-пулу.
+func validateGPU(spec, conts *yaml.Node, errs *[]ValidationError) {
+	policy := config.GPUPolicy
+	var requested *yaml.Node
+	for _, c := range conts.Content {
+		_, res := getMap(c, "resources")
+		_, limits := getMap(res, "limits")
+		_, requests := getMap(res, "requests")
+		for _, gpu := range policy.Resources {
+			_, lim := getMap(limits, gpu)
+			_, req := getMap(requests, gpu)
+			for i, v := range []*yaml.Node{lim, req} {
+				if v != nil && (v.Kind != yaml.ScalarNode || !wholeNumberRegex.MatchString(v.Value)) {
+					field := []string{"limits", "requests"}[i]
+					*errs = append(*errs, errAt(v, fmt.Sprintf("containers.resources.%s.%s must be a whole number", field, gpu)))
+				}
+			}
+			switch {
+			case req != nil && lim == nil:
+				*errs = append(*errs, warnAt(req, fmt.Sprintf("containers.resources.requests.%s is set without limits", gpu)))
+			case req != nil && lim != nil && req.Value != lim.Value:
+				*errs = append(*errs, errAt(req, fmt.Sprintf("containers.resources.requests.%s must equal limits (%s)", gpu, lim.Value)))
+			}
+			if requested == nil && (req != nil || lim != nil) {
+				requested = lim
+				if requested == nil {
+					requested = req
+				}
+			}
+		}
+	}
+	if requested == nil || policy.NodePoolLabel == "" {
+		return
+	}
+	_, sel := getMap(spec, "nodeSelector")
+	if k, _ := getMap(sel, policy.NodePoolLabel); k != nil {
+		return
+	}
+	_, tols := getMap(spec, "tolerations")
+	if tols != nil && tols.Kind == yaml.SequenceNode {
+		for _, t := range tols.Content {
+			if _, key := getMap(t, "key"); key != nil && (key.Value == policy.NodePoolLabel || contains(policy.Resources, key.Value)) {
+				return
+			}
+		}
+	}
+	e := warnAt(requested, fmt.Sprintf("pod requests GPU but has no nodeSelector or toleration for '%s'", policy.NodePoolLabel))
+	e.Hint = fmt.Sprintf("add spec.nodeSelector.%s or a toleration with key %s", policy.NodePoolLabel, policy.NodePoolLabel)
+	*errs = append(*errs, e)
+}
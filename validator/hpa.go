@@ -0,0 +1,138 @@
+package validator
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	hpaMetricTypes = []string{"Resource", "ContainerResource", "Pods", "Object", "External"}
+	hpaTargetTypes = []string{"Utilization", "Value", "AverageValue"}
+	// kind, на которые может ссылаться scaleTargetRef
+	scalableKinds = []string{"Deployment", "StatefulSet", "ReplicaSet"}
+)
+
+func validateHPASpec(spec *yaml.Node, errs *[]ValidationError) {
+	// scaleTargetRef (обязательное)
+	_, ref := getMap(spec, "scaleTargetRef")
+	if ref == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.scaleTargetRef is required"})
+	} else if expectType(ref, yaml.MappingNode, "spec.scaleTargetRef", errs) {
+		for _, f := range []string{"kind", "name"} {
+			_, v := getMap(ref, f)
+			if v == nil {
+				*errs = append(*errs, ValidationError{Msg: "spec.scaleTargetRef." + f + " is required"})
+			} else {
+				expectString(v, "spec.scaleTargetRef."+f, errs)
+			}
+		}
+	}
+
+	// minReplicas/maxReplicas
+	min, max := 1, -1
+	if _, v := getMap(spec, "minReplicas"); v != nil {
+		if n, ok := validateIntValue(v, "spec.minReplicas", errs); ok {
+			min = n
+		}
+	}
+	_, maxNode := getMap(spec, "maxReplicas")
+	if maxNode == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.maxReplicas is required"})
+	} else if n, ok := validateIntValue(maxNode, "spec.maxReplicas", errs); ok {
+		max = n
+	}
+	if max >= 0 && min > max {
+		*errs = append(*errs, errAt(maxNode, fmt.Sprintf("spec.maxReplicas %d is less than spec.minReplicas %d", max, min)))
+	}
+
+	// metrics (необязательное)
+	_, metrics := getMap(spec, "metrics")
+	if metrics == nil || !expectType(metrics, yaml.SequenceNode, "spec.metrics", errs) {
+		return
+	}
+	for _, m := range metrics.Content {
+		if m.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(m, "spec.metrics must be array"))
+			continue
+		}
+		validateHPAMetric(m, errs)
+	}
+}
+
+func validateHPAMetric(m *yaml.Node, errs *[]ValidationError) {
+	_, typ := getMap(m, "type")
+	if typ == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.metrics.type is required"})
+		return
+	}
+	if !expectString(typ, "spec.metrics.type", errs) {
+		return
+	}
+	if !contains(hpaMetricTypes, typ.Value) {
+		*errs = append(*errs, errAt(typ, fmt.Sprintf("spec.metrics.type has unsupported value '%s'", typ.Value)))
+		return
+	}
+	// ключ источника совпадает с типом: Resource -> resource, ContainerResource -> containerResource
+	key := string(typ.Value[0]+'a'-'A') + typ.Value[1:]
+	field := "spec.metrics." + key
+	_, src := getMap(m, key)
+	if src == nil {
+		*errs = append(*errs, errAt(typ, fmt.Sprintf("%s is required when type is '%s'", field, typ.Value)))
+		return
+	}
+	if !expectType(src, yaml.MappingNode, field, errs) {
+		return
+	}
+	_, target := getMap(src, "target")
+	if target == nil {
+		*errs = append(*errs, ValidationError{Msg: field + ".target is required"})
+		return
+	}
+	if !expectType(target, yaml.MappingNode, field+".target", errs) {
+		return
+	}
+	_, tt := getMap(target, "type")
+	if tt == nil {
+		*errs = append(*errs, ValidationError{Msg: field + ".target.type is required"})
+		return
+	}
+	if !expectString(tt, field+".target.type", errs) {
+		return
+	}
+	if !contains(hpaTargetTypes, tt.Value) {
+		*errs = append(*errs, errAt(tt, fmt.Sprintf("%s.target.type has unsupported value '%s'", field, tt.Value)))
+		return
+	}
+	if tt.Value == "Utilization" {
+		if typ.Value != "Resource" && typ.Value != "ContainerResource" {
+			*errs = append(*errs, errAt(tt, fmt.Sprintf("%s.target.type 'Utilization' is only allowed for resource metrics", field)))
+		}
+		_, u := getMap(target, "averageUtilization")
+		if u == nil {
+			*errs = append(*errs, errAt(tt, field+".target.averageUtilization is required"))
+			return
+		}
+		if n, ok := validateIntValue(u, field+".target.averageUtilization", errs); ok && n > 100 {
+			*errs = append(*errs, warnAt(u, fmt.Sprintf("%s.target.averageUtilization %d%% is above 100%% of requests", field, n)))
+		}
+	}
+}
+
+// validateHPATarget проверяет, что scaleTargetRef ссылается на объект из проверяемого набора.
+func validateHPATarget(top *yaml.Node, namespace string, objects map[objectRef]bool, errs *[]ValidationError) {
+	_, spec := getMap(top, "spec")
+	_, ref := getMap(spec, "scaleTargetRef")
+	_, kind := getMap(ref, "kind")
+	_, name := getMap(ref, "name")
+	if kind == nil || name == nil || kind.Kind != yaml.ScalarNode || name.Kind != yaml.ScalarNode {
+		return
+	}
+	if !contains(scalableKinds, kind.Value) {
+		*errs = append(*errs, errAt(kind, fmt.Sprintf("spec.scaleTargetRef.kind has unsupported value '%s'", kind.Value)))
+		return
+	}
+	if !objects[objectRef{kind: kind.Value, namespace: namespace, name: name.Value}] {
+		*errs = append(*errs, warnAt(name, fmt.Sprintf("spec.scaleTargetRef %s/%s is not found among validated documents", kind.Value, name.Value)))
+	}
+}
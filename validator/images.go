@@ -0,0 +1,226 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const archLabel = "kubernetes.io/arch"
+
+// validateImagePlatforms проверяет, что образы контейнеров запустятся на всех архитектурах флота:
+// образ либо из multi-arch allowlist, либо закреплён digest'ом при явном nodeSelector по архитектуре.
+func validateImagePlatforms(spec, conts *yaml.Node, errs *[]ValidationError) {
+	policy := config.ImagePolicy
+	if len(policy.Platforms) == 0 {
+		return
+	}
+	arch := ""
+	if _, sel := getMap(spec, "nodeSelector"); sel != nil {
+		if _, a := getMap(sel, archLabel); a != nil && a.Kind == yaml.ScalarNode {
+			arch = a.Value
+		}
+	}
+
+	for _, c := range conts.Content {
+		_, image := getMap(c, "image")
+		if image == nil || image.Kind != yaml.ScalarNode || image.Value == "" {
+			continue
+		}
+		multiArch := hasAnyPrefix(image.Value, policy.MultiArchImages)
+		if config.CheckImages && (multiArch || !strings.Contains(image.Value, "@")) {
+			got, err := inspectImagePlatforms(image.Value)
+			if err != nil {
+				*errs = append(*errs, warnAt(image, fmt.Sprintf("containers.image '%s' cannot be inspected: %v", image.Value, err)))
+			} else if missing := missingPlatforms(policy.Platforms, got); len(missing) > 0 {
+				if multiArch {
+					*errs = append(*errs, errAt(image, fmt.Sprintf("containers.image '%s' has no manifest for %s", image.Value, strings.Join(missing, ", "))))
+					continue
+				}
+			} else {
+				continue
+			}
+		}
+		if multiArch {
+			continue
+		}
+		if strings.Contains(image.Value, "@sha256:") && contains(policy.Platforms, arch) {
+			continue
+		}
+		*errs = append(*errs, errAt(image, fmt.Sprintf("containers.image '%s' must be multi-arch or pinned by digest with %s nodeSelector", image.Value, archLabel)))
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func missingPlatforms(want, got []string) []string {
+	var missing []string
+	for _, p := range want {
+		if !contains(got, p) {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// результаты инспекции реестра в пределах одного запуска
+var (
+	imagePlatformsCache = map[string][]string{}
+	imagePlatformsMu    sync.Mutex
+)
+
+var registryClient = &http.Client{Timeout: 10 * time.Second}
+
+var challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// inspectImagePlatforms запрашивает манифест образа по Registry HTTP API v2 и возвращает
+// архитектуры из image index; для одиночного манифеста список пуст.
+func inspectImagePlatforms(ref string) ([]string, error) {
+	imagePlatformsMu.Lock()
+	got, ok := imagePlatformsCache[ref]
+	imagePlatformsMu.Unlock()
+	if ok {
+		return got, nil
+	}
+	host, repo, tag, err := parseImageRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := registryFetch(fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repo, tag))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var index struct {
+		Manifests []struct {
+			Platform struct {
+				Architecture string `json:"architecture"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return nil, err
+	}
+	got = []string{}
+	for _, m := range index.Manifests {
+		if a := m.Platform.Architecture; a != "" && a != "unknown" && !contains(got, a) {
+			got = append(got, a)
+		}
+	}
+	imagePlatformsMu.Lock()
+	imagePlatformsCache[ref] = got
+	imagePlatformsMu.Unlock()
+	return got, nil
+}
+
+// parseImageRef разбирает ссылку host/repo[:tag|@digest]; без тега подразумевается latest.
+func parseImageRef(ref string) (host, repo, tag string, err error) {
+	host, repo, ok := strings.Cut(ref, "/")
+	if !ok {
+		return "", "", "", fmt.Errorf("no registry host in reference")
+	}
+	tag = "latest"
+	if i := strings.LastIndex(repo, "@"); i >= 0 {
+		repo, tag = repo[:i], repo[i+1:]
+	} else if i := strings.LastIndex(repo, ":"); i >= 0 {
+		repo, tag = repo[:i], repo[i+1:]
+	}
+	return host, repo, tag, nil
+}
+
+// registryFetch выполняет GET к реестру, при необходимости получив анонимный токен;
+// ответ с кодом, отличным от 200, возвращается как ошибка.
+func registryFetch(url string) (*http.Response, error) {
+	resp, err := registryGet(url, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		token, err := registryToken(resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = registryGet(url, token); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry returned %s", resp.Status)
+	}
+	return resp, nil
+}
+
+func registryGet(url, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join([]string{
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+	}, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return registryClient.Do(req)
+}
+
+// registryToken получает анонимный bearer-токен по заголовку WWW-Authenticate.
+func registryToken(challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported registry auth '%s'", scheme)
+	}
+	p := map[string]string{}
+	for _, m := range challengeParamRegex.FindAllStringSubmatch(params, -1) {
+		p[m[1]] = m[2]
+	}
+	req, err := http.NewRequest(http.MethodGet, p["realm"], nil)
+	if err != nil {
+		return "", err
+	}
+	q := req.URL.Query()
+	for _, k := range []string{"service", "scope"} {
+		if p[k] != "" {
+			q.Set(k, p[k])
+		}
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := registryClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token endpoint returned %s", resp.Status)
+	}
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", err
+	}
+	if tok.Token != "" {
+		return tok.Token, nil
+	}
+	return tok.AccessToken, nil
+}
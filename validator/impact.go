@@ -0,0 +1,139 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// runImpact — подкоманда impact: проверяет набор манифестов со старыми и новыми правилами
+// и печатает находки, которые появятся (+) или исчезнут (-) после смены правил.
+func runImpact(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("impact", flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	rulesOld := flags.String("rules-old", "", "directory of current rule bundles (default: built-in rules only)")
+	rulesNew := flags.String("rules-new", "", "directory of proposed rule bundles (default: built-in rules only)")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s impact [flags] <file-or-dir>...\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return 2
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(w, configFile(*configPath), err)
+		return 1
+	}
+	var files []string
+	for _, p := range flags.Args() {
+		found, err := manifestFiles(p)
+		if err != nil {
+			printIOErr(w, p, err)
+			return 1
+		}
+		files = append(files, found...)
+	}
+
+	before, err := findingsWithRules(*rulesOld, files)
+	if err != nil {
+		printIOErr(w, *rulesOld, err)
+		return 1
+	}
+	after, err := findingsWithRules(*rulesNew, files)
+	if err != nil {
+		printIOErr(w, *rulesNew, err)
+		return 1
+	}
+
+	added, removed := diffFindings(before, after), diffFindings(after, before)
+	for _, f := range removed {
+		fmt.Fprintln(w, "- "+f)
+	}
+	for _, f := range added {
+		fmt.Fprintln(w, "+ "+f)
+	}
+	fmt.Fprintf(w, "%d added, %d removed\n", len(added), len(removed))
+	return 0
+}
+
+// manifestFiles раскрывает каталог в список *.yaml/*.yml; файл возвращается как есть.
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(p); !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// findingsWithRules проверяет файлы со встроенными правилами и наборами из dir.
+// Файлы, которые не читаются или не разбираются, одинаково выпадают в обоих прогонах.
+func findingsWithRules(dir string, files []string) ([]string, error) {
+	resetRules()
+	if dir != "" {
+		if err := loadRulesDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	var manifests []*manifest
+	for _, file := range files {
+		if m, err := readManifest(file); err == nil {
+			manifests = append(manifests, m)
+		}
+	}
+	for _, m := range manifests {
+		validateManifest(m)
+	}
+	validateDocumentSet(manifests)
+
+	var out []string
+	for _, m := range manifests {
+		for _, e := range m.errs {
+			f := formatFinding(m.file, e)
+			if e.Line == 0 {
+				f = m.file + " " + f
+			}
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// diffFindings возвращает находки b, которых нет в a (с учётом повторов).
+func diffFindings(a, b []string) []string {
+	count := map[string]int{}
+	for _, f := range a {
+		count[f]++
+	}
+	var diff []string
+	for _, f := range b {
+		if count[f] > 0 {
+			count[f]--
+			continue
+		}
+		diff = append(diff, f)
+	}
+	sort.Strings(diff)
+	return diff
+}
@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	intotoStatementType = "https://in-toto.io/Statement/v1"
+	// тип предиката результата проверки
+	intotoPredicateType = "https://github.com/beezzlot/go-magist-repos2/validation/v1"
+)
+
+type intotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []intotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     intotoPredicate `json:"predicate"`
+}
+
+type intotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type intotoPredicate struct {
+	Validator struct {
+		Rules string `json:"rules"`
+	} `json:"validator"`
+	// passed — в манифестах нет ошибок (предупреждения допустимы)
+	Result    string        `json:"result"`
+	Errors    int           `json:"errors"`
+	Warnings  int           `json:"warnings"`
+	Timestamp string        `json:"timestamp"`
+	Results   []batchResult `json:"results"`
+}
+
+// intotoSink пишет итог прогона как утверждение in-toto, привязанное к SHA-256 проверенных
+// манифестов: конвейер поставки может требовать аттестацию "policy-validated" перед
+// выкладкой. Подпись — --sign-report или внешняя обёртка DSSE (cosign attest).
+type intotoSink struct {
+	outputFile
+	subjects []intotoSubject
+	pred     intotoPredicate
+}
+
+func (s *intotoSink) manifest(m *manifest) error {
+	sum := sha256.Sum256(m.src)
+	s.subjects = append(s.subjects, intotoSubject{Name: m.file, Digest: map[string]string{"sha256": hex.EncodeToString(sum[:])}})
+	res := batchResult{Filename: m.file, Findings: []jsonFinding{}}
+	for _, e := range m.errs {
+		if e.Severity == SeverityWarning {
+			s.pred.Warnings++
+		} else {
+			s.pred.Errors++
+		}
+		res.Findings = append(res.Findings, toJSONFinding(e))
+	}
+	s.pred.Results = append(s.pred.Results, res)
+	return nil
+}
+
+// непрочитанный файл не проверен: аттестация такого прогона — не passed
+func (s *intotoSink) fileError(file string, err error) error {
+	s.pred.Errors++
+	s.pred.Results = append(s.pred.Results, batchResult{Filename: file, Error: err.Error(), Findings: []jsonFinding{}})
+	return nil
+}
+
+func (s *intotoSink) close() error {
+	s.pred.Validator.Rules = rulesVersion()
+	s.pred.Result = "passed"
+	if s.pred.Errors > 0 {
+		s.pred.Result = "failed"
+	}
+	s.pred.Timestamp = now().UTC().Format(time.RFC3339)
+	if s.pred.Results == nil {
+		s.pred.Results = []batchResult{}
+	}
+	subjects := s.subjects
+	if subjects == nil {
+		subjects = []intotoSubject{}
+	}
+	return s.encodeJSON(intotoStatement{
+		Type: intotoStatementType, Subject: subjects,
+		PredicateType: intotoPredicateType, Predicate: s.pred,
+	})
+}
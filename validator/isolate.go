@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// правило находок о сбое самого валидатора на документе
+const internalErrorRule = "internal-error"
+
+// perDocTimeout — предел времени на проверку одного документа (--per-doc-timeout); 0 — без предела
+var perDocTimeout time.Duration
+
+// abandoned — проверки, брошенные по тайм-ауту и ещё не доработавшие. Следующий прогон
+// (resetState) ждёт их, прежде чем сбрасывать конфиг и правила, которые они читают.
+var abandoned sync.WaitGroup
+
+// validateDocument проверяет документ так, чтобы паника или зависание на одном
+// патологическом документе не обрывали весь прогон: сбой становится находкой internal-error.
+func validateDocument(top *yaml.Node, errs *[]ValidationError) {
+	if perDocTimeout <= 0 {
+		*errs = append(*errs, validateIsolated(top)...)
+		return
+	}
+	done := make(chan []ValidationError, 1)
+	abandoned.Add(1)
+	go func() {
+		defer abandoned.Done()
+		done <- validateIsolated(top)
+	}()
+	timer := time.NewTimer(perDocTimeout)
+	defer timer.Stop()
+	select {
+	case found := <-done:
+		*errs = append(*errs, found...)
+	case <-timer.C:
+		// горутину не остановить: она доработает сама, её находки остаются в done этого
+		// вызова и отбрасываются. Общего состояния она не пишет: профиль документа атомарен,
+		// а --timings вместе с --per-doc-timeout не допускается
+		*errs = append(*errs, internalError(top, fmt.Sprintf("validation timed out after %s", perDocTimeout)))
+	}
+}
+
+// validateIsolated выполняет проверки документа, превращая панику в находку;
+// найденное до паники сохраняется.
+func validateIsolated(top *yaml.Node) (found []ValidationError) {
+	defer func() {
+		if r := recover(); r != nil {
+			found = append(found, internalError(top, fmt.Sprintf("validation panicked: %v", r)))
+		}
+	}()
+	timeBuiltin(func() { validateTop(top, &found) })
+	return found
+}
+
+func internalError(top *yaml.Node, reason string) ValidationError {
+	e := errAt(top, "internal error: "+reason)
+	e.Rule = internalErrorRule
+	e.Hint = "the document was not fully validated; please report this with the document attached"
+	return e
+}
@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// registerTestKind регистрирует kind на время теста.
+func registerTestKind(t *testing.T, kind string, v KindValidator) {
+	t.Helper()
+	RegisterKind(GroupVersionKind{APIVersion: "test.example.com/v1", Kind: kind}, v)
+	t.Cleanup(func() {
+		delete(kinds, kind)
+		delete(apiVersions, kind)
+		delete(registeredKinds, kind)
+	})
+}
+
+func parseTestManifest(t *testing.T, src string) *manifest {
+	t.Helper()
+	m, err := parseManifest("test.yaml", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestValidateDocumentTimeout(t *testing.T) {
+	resetState()
+	release := make(chan struct{})
+	finished := make(chan string, 1)
+	registerTestKind(t, "Slow", func(obj *yaml.Node, errs *[]ValidationError) {
+		<-release
+		// брошенная горутина читает профиль, пока следующий документ выставляет свой
+		finished <- activeProfile()
+		*errs = append(*errs, ErrAt(obj, "late finding"))
+	})
+	perDocTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { perDocTimeout = 0 })
+
+	m := parseTestManifest(t, "apiVersion: test.example.com/v1\nkind: Slow\nmetadata:\n  name: x\n")
+	validateManifest(m)
+	if len(m.errs) != 1 || m.errs[0].Msg != "internal error: validation timed out after 20ms" || m.errs[0].Rule != internalErrorRule {
+		t.Fatalf("findings = %+v, want one timeout", m.errs)
+	}
+
+	// следующий документ проверяется одновременно с брошенной горутиной; под -race гонок нет
+	config.HonorAnnotations = true
+	next := parseTestManifest(t, "apiVersion: v1\nkind: Pod\nmetadata:\n  name: y\n  annotations:\n    validator.bigbrother.io/profile: restricted\nspec:\n  containers: []\n")
+	close(release)
+	validateManifest(next)
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("abandoned validation did not finish")
+	}
+	abandoned.Wait()
+	for _, e := range m.errs {
+		if e.Msg == "late finding" {
+			t.Error("finding of the abandoned validation leaked into the manifest")
+		}
+	}
+}
+
+func TestValidateDocumentPanic(t *testing.T) {
+	resetState()
+	registerTestKind(t, "Broken", func(obj *yaml.Node, errs *[]ValidationError) {
+		*errs = append(*errs, ErrAt(obj, "before panic"))
+		panic("boom")
+	})
+	m := parseTestManifest(t, "apiVersion: test.example.com/v1\nkind: Broken\nmetadata:\n  name: x\n")
+	validateManifest(m)
+	var got []string
+	for _, e := range m.errs {
+		got = append(got, e.Msg)
+	}
+	want := []string{"before panic", "internal error: validation panicked: boom"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+}
+
+func TestTimingsWithPerDocTimeoutRejected(t *testing.T) {
+	code, out := runCLI(t, "--timings", "--per-doc-timeout", "1s", "testdata/crossfile/deploy.yaml")
+	if code != 2 || !strings.Contains(out, "per-doc-timeout cannot be combined with timings") {
+		t.Errorf("got %d %q, want exit 2 with a usage error", code, out)
+	}
+}
@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+func validateJobSpec(spec *yaml.Node, errs *[]ValidationError) {
+	intField := func(f string) (int, bool) {
+		_, v := getMap(spec, f)
+		if v == nil {
+			return 0, false
+		}
+		return validateIntValue(v, "spec."+f, errs)
+	}
+	validateIntFields(spec, "spec.", errs, "backoffLimit", "activeDeadlineSeconds", "ttlSecondsAfterFinished")
+	completions, hasCompletions := intField("completions")
+	parallelism, hasParallelism := intField("parallelism")
+
+	if hasCompletions && hasParallelism && parallelism > completions {
+		_, p := getMap(spec, "parallelism")
+		*errs = append(*errs, warnAt(p, fmt.Sprintf("spec.parallelism %d is greater than spec.completions %d", parallelism, completions)))
+	}
+
+	// completionMode (необязательное)
+	mode := "NonIndexed"
+	if _, cm := getMap(spec, "completionMode"); cm != nil && expectString(cm, "spec.completionMode", errs) {
+		if cm.Value != "NonIndexed" && cm.Value != "Indexed" {
+			*errs = append(*errs, errAt(cm, fmt.Sprintf("spec.completionMode has unsupported value '%s'", cm.Value)))
+		} else {
+			mode = cm.Value
+		}
+		if cm.Value == "Indexed" && !hasCompletions {
+			*errs = append(*errs, errAt(cm, "spec.completions is required when completionMode is 'Indexed'"))
+		}
+	}
+	for _, f := range []string{"backoffLimitPerIndex", "maxFailedIndexes"} {
+		if k, v := getMap(spec, f); v != nil {
+			if mode != "Indexed" {
+				*errs = append(*errs, errAt(k, fmt.Sprintf("spec.%s is not allowed when completionMode is '%s'", f, mode)))
+			} else {
+				validateIntValue(v, "spec."+f, errs)
+			}
+		}
+	}
+
+	// template (обязательное)
+	_, tpl := getMap(spec, "template")
+	if tpl == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.template is required"})
+		return
+	}
+	if !expectType(tpl, yaml.MappingNode, "spec.template", errs) {
+		return
+	}
+	_, podSpec := getMap(tpl, "spec")
+	if podSpec == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.template.spec is required"})
+		return
+	}
+	if !expectType(podSpec, yaml.MappingNode, "spec.template.spec", errs) {
+		return
+	}
+	validatePodSpec(podSpec, errs)
+
+	// у пода Job restartPolicy может быть только Never или OnFailure
+	_, rp := getMap(podSpec, "restartPolicy")
+	if rp == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.template.spec.restartPolicy is required"})
+	} else if expectString(rp, "spec.template.spec.restartPolicy", errs) && rp.Value != "Never" && rp.Value != "OnFailure" {
+		*errs = append(*errs, errAt(rp, fmt.Sprintf("spec.template.spec.restartPolicy has unsupported value '%s'", rp.Value)))
+	}
+}
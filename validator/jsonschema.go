@@ -0,0 +1,294 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonSchema — JSON Schema kind'а в объёме, который используют схемы Kubernetes:
+// type, properties, required, additionalProperties, items, enum, pattern, границы,
+// allOf/anyOf/oneOf и локальные $ref. Внешние $ref не разрешаются, поэтому нужны
+// standalone-схемы.
+type jsonSchema struct {
+	root     map[string]any
+	patterns map[string]*regexp.Regexp
+}
+
+// названия типов JSON Schema в сообщениях валидатора
+var schemaTypeNames = map[string]string{
+	"object":  "object",
+	"array":   "list",
+	"string":  "string",
+	"integer": "int",
+	"number":  "number",
+	"boolean": "bool",
+	"null":    "null",
+}
+
+func (s *jsonSchema) validate(n *yaml.Node, field string, errs *[]ValidationError) {
+	s.check(n, s.root, field, errs)
+}
+
+func (s *jsonSchema) check(n *yaml.Node, sch map[string]any, field string, errs *[]ValidationError) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if ref, ok := sch["$ref"].(string); ok {
+		target := s.resolve(ref)
+		if target == nil {
+			return
+		}
+		sch = target
+	}
+	for _, sub := range schemaList(sch["allOf"]) {
+		s.check(n, sub, field, errs)
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if alts := schemaList(sch[key]); len(alts) > 0 {
+			s.checkAlternatives(n, alts, field, errs)
+		}
+	}
+	if !s.checkType(n, sch, field, errs) {
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		s.checkObject(n, sch, field, errs)
+	case yaml.SequenceNode:
+		if min, ok := sch["minItems"].(float64); ok && float64(len(n.Content)) < min {
+			*errs = append(*errs, errAt(n, field+" value out of range"))
+		}
+		if max, ok := sch["maxItems"].(float64); ok && float64(len(n.Content)) > max {
+			*errs = append(*errs, errAt(n, field+" value out of range"))
+		}
+		if items, ok := sch["items"].(map[string]any); ok {
+			for _, item := range n.Content {
+				s.check(item, items, field, errs)
+			}
+		}
+	case yaml.ScalarNode:
+		s.checkScalar(n, sch, field, errs)
+	}
+}
+
+// checkAlternatives: узел должен подойти хотя бы под одну из схем; иначе выводятся
+// находки первой из них.
+func (s *jsonSchema) checkAlternatives(n *yaml.Node, alts []map[string]any, field string, errs *[]ValidationError) {
+	var first []ValidationError
+	for i, alt := range alts {
+		var found []ValidationError
+		s.check(n, alt, field, &found)
+		if len(found) == 0 {
+			return
+		}
+		if i == 0 {
+			first = found
+		}
+	}
+	*errs = append(*errs, first...)
+}
+
+// checkType сверяет тип узла с type схемы; false — дальше проверять узел бессмысленно.
+func (s *jsonSchema) checkType(n *yaml.Node, sch map[string]any, field string, errs *[]ValidationError) bool {
+	var types []string
+	switch t := sch["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if v, ok := v.(string); ok {
+				types = append(types, v)
+			}
+		}
+	}
+	if intOrString, _ := sch["x-kubernetes-int-or-string"].(bool); intOrString {
+		types = []string{"integer", "string"}
+	}
+	if len(types) == 0 {
+		return true
+	}
+	got := nodeJSONType(n)
+	for _, t := range types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	if got == "null" && n.Kind == yaml.ScalarNode && (contains(types, "object") || contains(types, "array")) {
+		*errs = append(*errs, errAt(n, field+" must not be empty"))
+		return false
+	}
+	var names []string
+	for _, t := range types {
+		names = append(names, schemaTypeNames[t])
+	}
+	*errs = append(*errs, errAt(n, fmt.Sprintf("%s must be %s", field, strings.Join(names, " or "))))
+	return false
+}
+
+func (s *jsonSchema) checkObject(n *yaml.Node, sch map[string]any, field string, errs *[]ValidationError) {
+	props, _ := sch["properties"].(map[string]any)
+	for _, r := range schemaStrings(sch["required"]) {
+		if k, _ := getMap(n, r); k == nil {
+			*errs = append(*errs, errAt(n, joinField(field, r)+" is required"))
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if p, ok := props[k.Value].(map[string]any); ok {
+			s.check(v, p, joinField(field, k.Value), errs)
+			continue
+		}
+		switch ap := sch["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				*errs = append(*errs, errAt(k, joinField(field, k.Value)+" is not allowed"))
+			}
+		case map[string]any:
+			s.check(v, ap, joinField(field, k.Value), errs)
+		}
+	}
+}
+
+func (s *jsonSchema) checkScalar(n *yaml.Node, sch map[string]any, field string, errs *[]ValidationError) {
+	if enum, ok := sch["enum"].([]any); ok && len(enum) > 0 && !inSchemaEnum(n, enum) {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s has unsupported value '%s'", field, n.Value)))
+	}
+	switch nodeJSONType(n) {
+	case "string":
+		if p, ok := sch["pattern"].(string); ok {
+			if re := s.pattern(p); re != nil && !re.MatchString(formatValue(n)) {
+				*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, formatValue(n))))
+			}
+		}
+		l := float64(utf8.RuneCountInString(n.Value))
+		if min, ok := sch["minLength"].(float64); ok && l < min {
+			*errs = append(*errs, errAt(n, field+" value out of range"))
+		} else if max, ok := sch["maxLength"].(float64); ok && l > max {
+			*errs = append(*errs, errAt(n, field+" value out of range"))
+		}
+	case "integer", "number":
+		v, err := strconv.ParseFloat(strings.ReplaceAll(n.Value, "_", ""), 64)
+		if err != nil {
+			return
+		}
+		min, hasMin := sch["minimum"].(float64)
+		max, hasMax := sch["maximum"].(float64)
+		if (hasMin && v < min) || (hasMax && v > max) {
+			*errs = append(*errs, errAt(n, field+" value out of range"))
+		}
+	}
+}
+
+// resolve находит локальную ссылку "#/definitions/x"; для внешних возвращает nil.
+func (s *jsonSchema) resolve(ref string) map[string]any {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	var cur any = s.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	m, _ := cur.(map[string]any)
+	return m
+}
+
+// pattern компилирует регулярное выражение схемы один раз; несовместимые с RE2 пропускаются.
+func (s *jsonSchema) pattern(p string) *regexp.Regexp {
+	if s.patterns == nil {
+		s.patterns = map[string]*regexp.Regexp{}
+	}
+	re, ok := s.patterns[p]
+	if !ok {
+		re, _ = regexp.Compile(p)
+		s.patterns[p] = re
+	}
+	return re
+}
+
+// nodeJSONType — тип узла в терминах JSON Schema.
+func nodeJSONType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch scalarType(n) {
+	case "bool":
+		return "boolean"
+	case "int":
+		return "integer"
+	case "float":
+		v, err := strconv.ParseFloat(n.Value, 64)
+		if err == nil && v == math.Trunc(v) && !strings.ContainsAny(n.Value, ".eE") {
+			return "integer"
+		}
+		return "number"
+	case "null":
+		return "null"
+	}
+	return "string"
+}
+
+func inSchemaEnum(n *yaml.Node, enum []any) bool {
+	for _, e := range enum {
+		switch e := e.(type) {
+		case string:
+			if nodeJSONType(n) == "string" && e == n.Value {
+				return true
+			}
+		case float64:
+			if v, err := strconv.ParseFloat(n.Value, 64); err == nil && v == e {
+				return true
+			}
+		case bool:
+			if nodeJSONType(n) == "boolean" && strconv.FormatBool(e) == strings.ToLower(n.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func schemaList(v any) []map[string]any {
+	list, _ := v.([]any)
+	var out []map[string]any
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func schemaStrings(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func joinField(field, key string) string {
+	if field == "" {
+		return key
+	}
+	return field + "." + key
+}
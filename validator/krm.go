@@ -0,0 +1,195 @@
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// аннотация kpt/kustomize с номером документа ресурса в исходном файле
+const originIndexAnnotation = "config.kubernetes.io/index"
+
+// krmResult — элемент results в ResourceList по спецификации KRM Functions
+type krmResult struct {
+	Message     string            `yaml:"message"`
+	Severity    string            `yaml:"severity"`
+	ResourceRef *krmResourceRef   `yaml:"resourceRef,omitempty"`
+	Field       *krmField         `yaml:"field,omitempty"`
+	File        *krmFile          `yaml:"file,omitempty"`
+	Tags        map[string]string `yaml:"tags,omitempty"`
+}
+
+type krmResourceRef struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+	Namespace  string `yaml:"namespace,omitempty"`
+}
+
+type krmField struct {
+	Path string `yaml:"path"`
+}
+
+type krmFile struct {
+	Path  string `yaml:"path"`
+	Index int    `yaml:"index"`
+}
+
+// runKRM — подкоманда krm: валидатор как функция kpt/kustomize. Читает ResourceList
+// со stdin, пишет его же в w с находками в results; ресурсы не меняются.
+func runKRM(args []string, r io.Reader, w io.Writer) int {
+	flags := flag.NewFlagSet("krm", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s krm [flags] < resource-list.yaml\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	// w занят ResourceList, ошибки — в stderr, как ждут kpt и kustomize
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(os.Stderr, configFile(*configPath), err)
+		return 1
+	}
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			printIOErr(os.Stderr, config.RulesDir, err)
+			return 1
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			printIOErr(os.Stderr, config.Rules, err)
+			return 1
+		}
+	}
+	src, err := io.ReadAll(r)
+	if err != nil {
+		printIOErr(os.Stderr, stdinName, err)
+		return 1
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(src, &root); err != nil {
+		printIOErr(os.Stderr, stdinName, err)
+		return 1
+	}
+	list := &root
+	if list.Kind == yaml.DocumentNode && len(list.Content) > 0 {
+		list = list.Content[0]
+	}
+	_, kind := getMap(list, "kind")
+	_, items := getMap(list, "items")
+	if kind == nil || kind.Value != "ResourceList" || (items != nil && items.Kind != yaml.SequenceNode) {
+		printIOErr(os.Stderr, stdinName, errors.New("input is not a ResourceList"))
+		return 1
+	}
+
+	// каждый ресурс — отдельный манифест; строки находок — строки всего ResourceList
+	var manifests []*manifest
+	if items != nil {
+		for _, item := range items.Content {
+			if item.Kind != yaml.MappingNode {
+				continue
+			}
+			manifests = append(manifests, &manifest{file: stdinName, src: src, docs: []*yaml.Node{item}})
+		}
+	}
+	for _, m := range manifests {
+		validateManifest(m)
+	}
+	validateDocumentSet(manifests)
+	results := []krmResult{}
+	failed := false
+	for _, m := range manifests {
+		fingerprintFindings(m)
+		applyExceptions(m)
+		applyMessageTemplates(m)
+		for _, e := range m.errs {
+			results = append(results, toKRMResult(m.docs[0], e))
+			if e.Severity != SeverityWarning {
+				failed = true
+			}
+		}
+	}
+
+	var resNode yaml.Node
+	if err := resNode.Encode(results); err != nil {
+		printIOErr(os.Stderr, stdinName, err)
+		return 1
+	}
+	setMapValue(list, "results", &resNode)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		printIOErr(os.Stderr, stdinName, err)
+		return 1
+	}
+	enc.Close()
+	w.Write(buf.Bytes())
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func toKRMResult(top *yaml.Node, e ValidationError) krmResult {
+	res := krmResult{Message: e.Msg, Severity: "error", Tags: map[string]string{"rule": RuleID(e)}}
+	if e.Severity == SeverityWarning {
+		res.Severity = "warning"
+	}
+	if e.Hint != "" {
+		res.Message += " (hint: " + e.Hint + ")"
+	}
+	if e.Fingerprint != "" {
+		res.Tags["fingerprint"] = e.Fingerprint
+	}
+	_, meta := getMap(top, "metadata")
+	if kind := scalarAt(top, "kind"); kind != "" {
+		res.ResourceRef = &krmResourceRef{
+			APIVersion: scalarAt(top, "apiVersion"), Kind: kind,
+			Name: scalarAt(meta, "name"), Namespace: scalarAt(meta, "namespace"),
+		}
+	}
+	if e.Path != nil {
+		res.Field = &krmField{Path: e.Path.String()}
+	}
+	if path := originAnnotation(top); path != "" {
+		res.File = &krmFile{Path: path}
+		_, ann := getMap(meta, "annotations")
+		if idx, err := strconv.Atoi(scalarAt(ann, originIndexAnnotation)); err == nil {
+			res.File.Index = idx
+		}
+	}
+	return res
+}
+
+// scalarAt — значение скалярного ключа mapping'а или "".
+func scalarAt(n *yaml.Node, key string) string {
+	if _, v := getMap(n, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// setMapValue заменяет значение ключа mapping'а или добавляет ключ в конец.
+func setMapValue(m *yaml.Node, key string, v *yaml.Node) {
+	if k, old := getMap(m, key); k != nil {
+		*old = *v
+		return
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+}
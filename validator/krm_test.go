@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resourceList оборачивает манифесты в ResourceList, как его передают kpt и kustomize.
+func resourceList(docs ...string) string {
+	var b strings.Builder
+	b.WriteString("apiVersion: config.kubernetes.io/v1\nkind: ResourceList\nitems:\n")
+	for _, doc := range docs {
+		for i, l := range strings.Split(strings.TrimSuffix(doc, "\n"), "\n") {
+			if i == 0 {
+				b.WriteString("  - " + l + "\n")
+			} else {
+				b.WriteString("    " + l + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func TestRunKRM(t *testing.T) {
+	configPath := teamRulesConfig(t)
+
+	tests := []struct {
+		name      string
+		args      []string
+		input     string
+		wantCode  int
+		wantRules []string
+		wantNames []string
+	}{
+		{
+			name:     "valid resources",
+			input:    resourceList(testPod("good", "linux")),
+			wantCode: 0,
+		},
+		{
+			name:      "findings become results",
+			input:     resourceList(testPod("good", "linux"), testPod("bad", "Vyp")),
+			wantCode:  1,
+			wantRules: []string{"pod-os"},
+			wantNames: []string{"bad"},
+		},
+		{
+			name:      "rules from config",
+			args:      []string{"--config", configPath},
+			input:     resourceList(testPod("good", "linux")),
+			wantCode:  1,
+			wantRules: []string{"team-label"},
+			wantNames: []string{"good"},
+		},
+		{
+			name:     "not a resource list",
+			input:    testPod("good", "linux"),
+			wantCode: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetState()
+			var out bytes.Buffer
+			code := runKRM(tt.args, strings.NewReader(tt.input), &out)
+			if code != tt.wantCode {
+				t.Errorf("code = %d, want %d; output:\n%s", code, tt.wantCode, out.String())
+			}
+			if out.Len() == 0 {
+				return
+			}
+			var list struct {
+				Items   []yaml.Node `yaml:"items"`
+				Results []krmResult `yaml:"results"`
+			}
+			if err := yaml.Unmarshal(out.Bytes(), &list); err != nil {
+				t.Fatal(err)
+			}
+			var rules, names []string
+			for _, r := range list.Results {
+				rules = append(rules, r.Tags["rule"])
+				names = append(names, r.ResourceRef.Name)
+			}
+			if strings.Join(rules, ",") != strings.Join(tt.wantRules, ",") || strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("results for rules %v of %v, want %v of %v", rules, names, tt.wantRules, tt.wantNames)
+			}
+			if want := strings.Count(tt.input, "kind: Pod"); len(list.Items) != want {
+				t.Errorf("items = %d, want %d", len(list.Items), want)
+			}
+		})
+	}
+}
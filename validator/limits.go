@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Limits struct {
+	// Максимум контейнеров в поде (containers + initContainers)
+	MaxContainers int `yaml:"maxContainers"`
+	// Максимум переменных env в одном контейнере
+	MaxEnvVars int `yaml:"maxEnvVars"`
+	// Максимальный размер манифеста в байтах
+	MaxManifestBytes int `yaml:"maxManifestBytes"`
+}
+
+// validatePodLimits ловит сгенерированные манифесты, которые пошли вразнос; 0 отключает лимит.
+func validatePodLimits(spec *yaml.Node, errs *[]ValidationError) {
+	limits := config.Limits
+	total := 0
+	var last *yaml.Node
+	for _, list := range []string{"initContainers", "containers"} {
+		_, conts := getMap(spec, list)
+		if conts == nil || conts.Kind != yaml.SequenceNode {
+			continue
+		}
+		total += len(conts.Content)
+		last = conts
+		for _, c := range conts.Content {
+			_, env := getMap(c, "env")
+			if limits.MaxEnvVars > 0 && env != nil && env.Kind == yaml.SequenceNode && len(env.Content) > limits.MaxEnvVars {
+				*errs = append(*errs, warnAt(env, fmt.Sprintf("%s.env has %d entries, more than %d", list, len(env.Content), limits.MaxEnvVars)))
+			}
+		}
+	}
+	if limits.MaxContainers > 0 && total > limits.MaxContainers {
+		*errs = append(*errs, warnAt(last, fmt.Sprintf("spec has %d containers, more than %d", total, limits.MaxContainers)))
+	}
+}
+
+func validateManifestSize(size int, errs *[]ValidationError) {
+	if max := config.Limits.MaxManifestBytes; max > 0 && size > max {
+		*errs = append(*errs, ValidationError{
+			Msg:      fmt.Sprintf("manifest is %d bytes, more than %d", size, max),
+			Severity: SeverityWarning,
+		})
+	}
+}
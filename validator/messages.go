@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// messageData — поля шаблона сообщения из messages конфига:
+// "image {{.Value}} must come from the corporate registry"
+type messageData struct {
+	// значение узла находки (для скаляров)
+	Value string
+	// путь к узлу в документе: spec.containers[0].image
+	Path string
+	// поле из исходного сообщения: containers.image
+	Field string
+	// исходное сообщение
+	Message string
+}
+
+func parseMessageTemplates(messages map[string]string) (map[string]*template.Template, error) {
+	out := map[string]*template.Template{}
+	for id, text := range messages {
+		t, err := template.New(id).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("messages %s: %w", id, err)
+		}
+		out[id] = t
+	}
+	return out, nil
+}
+
+// applyMessageTemplates заменяет сообщения находок шаблонами их правил. Идентификатор
+// правила фиксируется до замены, так как у встроенных проверок он выводится из сообщения.
+func applyMessageTemplates(m *manifest) {
+	if len(config.messages) == 0 {
+		return
+	}
+	for i := range m.errs {
+		e := &m.errs[i]
+		id := RuleID(*e)
+		t, ok := config.messages[id]
+		if !ok {
+			continue
+		}
+		field, _, _ := strings.Cut(e.Msg, " ")
+		p, _ := m.pathOf(e.node)
+		data := messageData{Field: field, Message: e.Msg, Path: p.path.String()}
+		if e.node != nil && e.node.Kind == yaml.ScalarNode {
+			data.Value = e.node.Value
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			continue
+		}
+		e.Rule = id
+		e.Msg = buf.String()
+	}
+}
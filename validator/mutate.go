@@ -0,0 +1,274 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mutationTarget — пара ключ/значение mapping'а, которую можно изменить
+type mutationTarget struct {
+	doc    int
+	parent *yaml.Node
+	// индекс ключа в parent.Content
+	index int
+	path  FieldPath
+	// поле обязательно по API Kubernetes: его удаление — настоящая порча
+	required bool
+}
+
+// mutant — вариант манифеста с одной мутацией
+type mutant struct {
+	desc string
+	line int
+	docs []*yaml.Node
+}
+
+// runMutate — подкоманда mutate: портит корректный манифест и проверяет,
+// что валидатор замечает каждую порчу. Выводит выжившие мутанты.
+func runMutate(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("mutate", flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	count := flags.Int("count", 100, "number of mutants")
+	seed := flags.Int64("seed", 1, "random seed; the same seed gives the same mutants")
+	outDir := flags.String("out", "", "directory to write surviving mutants to")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s mutate [flags] <good.yaml>\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	// файл может идти перед флагами: mutate good.yaml --count 100
+	var file string
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		file, args = args[0], args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if file == "" && flags.NArg() == 1 {
+		file = flags.Arg(0)
+	}
+	if file == "" || *count < 1 {
+		flags.Usage()
+		return 2
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(w, configFile(*configPath), err)
+		return 1
+	}
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			printIOErr(w, config.RulesDir, err)
+			return 1
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			printIOErr(w, config.Rules, err)
+			return 1
+		}
+	}
+	m, err := readManifest(file)
+	if err != nil {
+		printIOErr(w, file, err)
+		return 1
+	}
+	baseline := findingSet(m.file, m.docs)
+
+	rng := rand.New(rand.NewSource(*seed))
+	survived := 0
+	for i := 0; i < *count; i++ {
+		mu := mutate(m.docs, rng)
+		if mu == nil {
+			fmt.Fprintln(w, "nothing to mutate")
+			return 1
+		}
+		found := findingSet(m.file, mu.docs)
+		if detected(baseline, found) {
+			continue
+		}
+		survived++
+		fmt.Fprintf(w, "survived: %s (line %d)\n", mu.desc, mu.line)
+		if *outDir != "" {
+			if err := writeMutant(*outDir, file, i, mu); err != nil {
+				printIOErr(w, *outDir, err)
+				return 1
+			}
+		}
+	}
+	fmt.Fprintf(w, "%d mutants, %d detected, %d survived\n", *count, *count-survived, survived)
+	if survived > 0 {
+		return 1
+	}
+	return 0
+}
+
+// findingSet проверяет документы и возвращает сообщения находок с числом повторов.
+func findingSet(file string, docs []*yaml.Node) map[string]int {
+	m := &manifest{file: file, docs: docs}
+	validateManifest(m)
+	validateDocumentSet([]*manifest{m})
+	set := map[string]int{}
+	for _, e := range m.errs {
+		set[e.Msg]++
+	}
+	return set
+}
+
+// detected — у мутанта есть находка, которой не было у исходного файла.
+func detected(baseline, found map[string]int) bool {
+	for msg, n := range found {
+		if n > baseline[msg] {
+			return true
+		}
+	}
+	return false
+}
+
+// mutate копирует документы и применяет к копии одну случайную мутацию:
+// удаление обязательного поля, смену типа значения или недопустимое значение.
+// Необязательные поля не удаляются: такой мутант корректен и «выживал» бы зря.
+func mutate(docs []*yaml.Node, rng *rand.Rand) *mutant {
+	clones := make([]*yaml.Node, len(docs))
+	var targets, required []mutationTarget
+	for i, d := range docs {
+		clones[i] = cloneNode(d)
+		targets = collectTargets(clones[i], i, nil, targets)
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	for _, t := range targets {
+		if t.required {
+			required = append(required, t)
+		}
+	}
+	op := rng.Intn(3)
+	if op == 0 && len(required) == 0 {
+		op = 1 + rng.Intn(2)
+	}
+	var t mutationTarget
+	if op == 0 {
+		t = required[rng.Intn(len(required))]
+	} else {
+		t = targets[rng.Intn(len(targets))]
+	}
+	k, v := t.parent.Content[t.index], t.parent.Content[t.index+1]
+	mu := &mutant{docs: clones, line: k.Line}
+	switch op {
+	case 0:
+		t.parent.Content = append(t.parent.Content[:t.index:t.index], t.parent.Content[t.index+2:]...)
+		mu.desc = "delete " + t.path.String()
+	case 1:
+		mu.desc = "flip type of " + t.path.String()
+		if v.Kind == yaml.ScalarNode {
+			// скаляр становится mapping'ом, mapping и список — строкой
+			*v = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: v.Line, Column: v.Column}
+		} else {
+			*v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "mutated", Line: v.Line, Column: v.Column}
+		}
+	default:
+		mu.desc = "bad value of " + t.path.String()
+		if v.Kind == yaml.ScalarNode && scalarType(v) == "int" {
+			v.Value = "-" + strconv.Itoa(1+rng.Intn(99999))
+		} else {
+			*v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "__mutated__", Line: v.Line, Column: v.Column}
+		}
+	}
+	return mu
+}
+
+func collectTargets(n *yaml.Node, doc int, path FieldPath, targets []mutationTarget) []mutationTarget {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			p := path.Key(n.Content[i].Value)
+			targets = append(targets, mutationTarget{doc: doc, parent: n, index: i, path: p, required: isRequiredField(p)})
+			targets = collectTargets(n.Content[i+1], doc, p, targets)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			targets = collectTargets(item, doc, path.Index(i), targets)
+		}
+	}
+	return targets
+}
+
+func cloneNode(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = cloneNode(child)
+	}
+	return &c
+}
+
+func writeMutant(dir, file string, i int, mu *mutant) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	var out []byte
+	for j, d := range mu.docs {
+		b, err := yaml.Marshal(d)
+		if err != nil {
+			return err
+		}
+		if j > 0 {
+			out = append(out, "---\n"...)
+		}
+		out = append(out, b...)
+	}
+	ext := filepath.Ext(file)
+	name := fmt.Sprintf("%s.mutant%d%s", filepath.Base(file[:len(file)-len(ext)]), i, ext)
+	return os.WriteFile(filepath.Join(dir, name), out, 0o644)
+}
+
+// обязательные поля по API Kubernetes: пути от корня документа и окончания путей
+// (поля контейнеров, портов и ссылок, где бы ни лежала спецификация пода); [] — любой индекс
+var (
+	requiredPaths = []string{
+		"apiVersion", "kind", "metadata", "metadata.name",
+		"spec.selector", "spec.template", "spec.jobTemplate", "spec.jobTemplate.spec.template", "spec.schedule",
+		"spec.scaleTargetRef", "spec.maxReplicas", "spec.ports[].port", "roleRef",
+	}
+	requiredSuffixes = []string{
+		"spec.containers", "containers[].name", "containers[].image",
+		"initContainers[].name", "initContainers[].image",
+		"ports[].containerPort", "env[].name", "volumeMounts[].name", "volumeMounts[].mountPath", "volumes[].name",
+		"httpGet.port", "tcpSocket.port", "grpc.port", "exec.command",
+		"scaleTargetRef.kind", "scaleTargetRef.name",
+		"roleRef.kind", "roleRef.name", "roleRef.apiGroup", "subjects[].kind", "subjects[].name", "rules[].verbs",
+	}
+)
+
+// isRequiredField сообщает, обязательно ли поле с путём p.
+func isRequiredField(p FieldPath) bool {
+	var b strings.Builder
+	for _, s := range p {
+		if s.Index >= 0 {
+			b.WriteString("[]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(s.Key)
+	}
+	path := b.String()
+	if contains(requiredPaths, path) {
+		return true
+	}
+	for _, suffix := range requiredSuffixes {
+		if path == suffix || strings.HasSuffix(path, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestIsRequiredField(t *testing.T) {
+	tests := []struct {
+		path FieldPath
+		want bool
+	}{
+		{FieldPath{}.Key("apiVersion"), true},
+		{FieldPath{}.Key("metadata").Key("name"), true},
+		{FieldPath{}.Key("metadata").Key("labels"), false},
+		{FieldPath{}.Key("spec").Key("containers").Index(0).Key("image"), true},
+		{FieldPath{}.Key("spec").Key("template").Key("spec").Key("containers").Index(2).Key("name"), true},
+		{FieldPath{}.Key("spec").Key("template").Key("spec").Key("containers").Index(0).Key("resources"), false},
+		{FieldPath{}.Key("spec").Key("replicas"), false},
+		{FieldPath{}.Key("spec").Key("ports").Index(0).Key("port"), true},
+		{FieldPath{}.Key("spec").Key("ports").Index(0).Key("targetPort"), false},
+		{FieldPath{}.Key("spec").Key("ingress").Index(0).Key("ports").Index(0).Key("port"), false},
+	}
+	for _, tt := range tests {
+		if got := isRequiredField(tt.path); got != tt.want {
+			t.Errorf("isRequiredField(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// Мутант с удалённым полем всегда теряет обязательное поле.
+func TestMutateDeletesOnlyRequiredFields(t *testing.T) {
+	b, err := os.ReadFile("testdata/crossfile/deploy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	docs := []*yaml.Node{doc.Content[0]}
+	rng := rand.New(rand.NewSource(1))
+	deletions := 0
+	for i := 0; i < 300; i++ {
+		mu := mutate(docs, rng)
+		path, ok := strings.CutPrefix(mu.desc, "delete ")
+		if !ok {
+			continue
+		}
+		deletions++
+		var required bool
+		for _, tgt := range collectTargets(docs[0], 0, nil, nil) {
+			if tgt.path.String() == path {
+				required = tgt.required
+			}
+		}
+		if !required {
+			t.Errorf("mutant deletes optional field %s", path)
+		}
+	}
+	if deletions == 0 {
+		t.Error("no deletion mutants generated")
+	}
+}
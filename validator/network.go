@@ -0,0 +1,144 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DNS-имя по RFC 1123: метки из [a-z0-9-], разделённые точками
+var dnsSubdomainRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+func validateIP(n *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectString(n, field, errs) {
+		return
+	}
+	if net.ParseIP(n.Value) == nil {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, n.Value)))
+	}
+}
+
+func validateDNSName(n *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectString(n, field, errs) {
+		return
+	}
+	if len(n.Value) > 253 || !dnsSubdomainRegex.MatchString(n.Value) {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, n.Value)))
+	}
+}
+
+func validateHostAliases(n *yaml.Node, errs *[]ValidationError) {
+	if !expectType(n, yaml.SequenceNode, "spec.hostAliases", errs) {
+		return
+	}
+	for _, item := range n.Content {
+		if item.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(item, "spec.hostAliases must be array"))
+			continue
+		}
+		_, ip := getMap(item, "ip")
+		if ip == nil {
+			*errs = append(*errs, ValidationError{Msg: "spec.hostAliases.ip is required"})
+		} else {
+			validateIP(ip, "spec.hostAliases.ip", errs)
+		}
+
+		_, hostnames := getMap(item, "hostnames")
+		if hostnames == nil {
+			*errs = append(*errs, ValidationError{Msg: "spec.hostAliases.hostnames is required"})
+		} else if expectNonEmpty(hostnames, yaml.SequenceNode, "spec.hostAliases.hostnames", errs) {
+			for _, h := range hostnames.Content {
+				validateDNSName(h, "spec.hostAliases.hostnames", errs)
+			}
+		}
+	}
+}
+
+func validateDNSConfig(n *yaml.Node, errs *[]ValidationError) {
+	if !expectType(n, yaml.MappingNode, "spec.dnsConfig", errs) {
+		return
+	}
+	if _, ns := getMap(n, "nameservers"); ns != nil {
+		if expectType(ns, yaml.SequenceNode, "spec.dnsConfig.nameservers", errs) {
+			for _, ip := range ns.Content {
+				validateIP(ip, "spec.dnsConfig.nameservers", errs)
+			}
+		}
+	}
+	if _, searches := getMap(n, "searches"); searches != nil {
+		if expectType(searches, yaml.SequenceNode, "spec.dnsConfig.searches", errs) {
+			for _, s := range searches.Content {
+				validateDNSName(s, "spec.dnsConfig.searches", errs)
+			}
+		}
+	}
+	if _, opts := getMap(n, "options"); opts != nil {
+		if !expectType(opts, yaml.SequenceNode, "spec.dnsConfig.options", errs) {
+			return
+		}
+		for _, o := range opts.Content {
+			if o.Kind != yaml.MappingNode {
+				*errs = append(*errs, errAt(o, "spec.dnsConfig.options must be array"))
+				continue
+			}
+			_, name := getMap(o, "name")
+			if name == nil {
+				*errs = append(*errs, ValidationError{Msg: "spec.dnsConfig.options.name is required"})
+			} else {
+				expectString(name, "spec.dnsConfig.options.name", errs)
+			}
+			if _, val := getMap(o, "value"); val != nil {
+				expectType(val, yaml.ScalarNode, "spec.dnsConfig.options.value", errs)
+			}
+		}
+	}
+}
+
+// validateHostPortConflicts ищет одинаковые hostPort/protocol в разных портах пода;
+// пустой hostIP (или 0.0.0.0) занимает порт на всех адресах.
+func validateHostPortConflicts(conts *yaml.Node, errs *[]ValidationError) {
+	type binding struct {
+		ip   string
+		node *yaml.Node
+	}
+	used := map[string][]binding{}
+	for _, c := range conts.Content {
+		_, ports := getMap(c, "ports")
+		if ports == nil || ports.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, p := range ports.Content {
+			_, hport := getMap(p, "hostPort")
+			if hport == nil || hport.Kind != yaml.ScalarNode {
+				continue
+			}
+			if val, err := strconv.Atoi(hport.Value); err != nil || val <= 0 {
+				continue
+			}
+			proto := "TCP"
+			if _, pr := getMap(p, "protocol"); pr != nil && pr.Kind == yaml.ScalarNode {
+				proto = strings.ToUpper(pr.Value)
+			}
+			ip := ""
+			if _, hip := getMap(p, "hostIP"); hip != nil && hip.Kind == yaml.ScalarNode && hip.Value != "0.0.0.0" {
+				ip = hip.Value
+			}
+			key := hport.Value + "/" + proto
+			conflict := false
+			for _, b := range used[key] {
+				if b.ip == ip || b.ip == "" || ip == "" {
+					*errs = append(*errs, errAt(hport, fmt.Sprintf("hostPort %s conflicts with line %d", key, b.node.Line)))
+					conflict = true
+					break
+				}
+			}
+			if !conflict {
+				used[key] = append(used[key], binding{ip: ip, node: hport})
+			}
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	dnsLabelRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// IANA_SVC_NAME: до 15 символов, хотя бы одна буква, без двойных дефисов
+	portNameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+	pathTypes     = []string{"Exact", "Prefix", "ImplementationSpecific"}
+	policyTypes   = []string{"Ingress", "Egress"}
+)
+
+func isPortName(s string) bool {
+	return len(s) <= 15 && portNameRegex.MatchString(s) && !strings.Contains(s, "--") && strings.ContainsAny(s, "abcdefghijklmnopqrstuvwxyz")
+}
+
+// validatePortRef проверяет порт вида IntOrString (порты проб, targetPort сервиса,
+// порты NetworkPolicy): число в [1, 65535] или имя порта. Возвращает номер корректного
+// числового порта либо имя; при ошибке оба пустые.
+func validatePortRef(n *yaml.Node, field string, errs *[]ValidationError) (num int, name string) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		if !isPortName(n.Value) {
+			*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, n.Value)))
+			return 0, ""
+		}
+		return 0, n.Value
+	}
+	if n.Kind != yaml.ScalarNode {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, n.Value)))
+		return 0, ""
+	}
+	num, _ = validateIntValue(n, field, errs)
+	return num, ""
+}
+
+// validateIngressHost допускает wildcard только в первой метке и не допускает IP-адреса.
+func validateIngressHost(n *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectString(n, field, errs) {
+		return
+	}
+	host := strings.TrimPrefix(n.Value, "*.")
+	if net.ParseIP(n.Value) != nil || len(host) > 253 || !dnsSubdomainRegex.MatchString(host) {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, n.Value)))
+	}
+}
+
+func validateIngressSpec(spec *yaml.Node, errs *[]ValidationError) {
+	if _, cls := getMap(spec, "ingressClassName"); cls != nil {
+		validateDNSName(cls, "spec.ingressClassName", errs)
+	}
+	_, def := getMap(spec, "defaultBackend")
+	if def != nil {
+		validateIngressBackend(def, "spec.defaultBackend", errs)
+	}
+
+	if _, tls := getMap(spec, "tls"); tls != nil && expectType(tls, yaml.SequenceNode, "spec.tls", errs) {
+		for _, t := range tls.Content {
+			if t.Kind != yaml.MappingNode {
+				*errs = append(*errs, errAt(t, "spec.tls must be array"))
+				continue
+			}
+			if _, hosts := getMap(t, "hosts"); hosts != nil && expectType(hosts, yaml.SequenceNode, "spec.tls.hosts", errs) {
+				for _, h := range hosts.Content {
+					validateIngressHost(h, "spec.tls.hosts", errs)
+				}
+			}
+			if _, secret := getMap(t, "secretName"); secret != nil {
+				validateDNSName(secret, "spec.tls.secretName", errs)
+			}
+		}
+	}
+
+	_, rules := getMap(spec, "rules")
+	if rules == nil {
+		if def == nil {
+			*errs = append(*errs, ValidationError{Msg: "spec.rules is required"})
+		}
+		return
+	}
+	if !expectType(rules, yaml.SequenceNode, "spec.rules", errs) {
+		return
+	}
+	for _, r := range rules.Content {
+		if r.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(r, "spec.rules must be array"))
+			continue
+		}
+		if _, host := getMap(r, "host"); host != nil {
+			validateIngressHost(host, "spec.rules.host", errs)
+		}
+		_, http := getMap(r, "http")
+		if http == nil || !expectType(http, yaml.MappingNode, "spec.rules.http", errs) {
+			continue
+		}
+		_, paths := getMap(http, "paths")
+		if paths == nil {
+			*errs = append(*errs, ValidationError{Msg: "spec.rules.http.paths is required"})
+			continue
+		}
+		if !expectNonEmpty(paths, yaml.SequenceNode, "spec.rules.http.paths", errs) {
+			continue
+		}
+		for _, p := range paths.Content {
+			if p.Kind != yaml.MappingNode {
+				*errs = append(*errs, errAt(p, "spec.rules.http.paths must be array"))
+				continue
+			}
+			validateIngressPath(p, errs)
+		}
+	}
+}
+
+func validateIngressPath(p *yaml.Node, errs *[]ValidationError) {
+	_, pt := getMap(p, "pathType")
+	if pt == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.rules.http.paths.pathType is required"})
+	} else if expectString(pt, "spec.rules.http.paths.pathType", errs) && !contains(pathTypes, pt.Value) {
+		*errs = append(*errs, errAt(pt, fmt.Sprintf("spec.rules.http.paths.pathType has unsupported value '%s'", pt.Value)))
+	}
+	if _, path := getMap(p, "path"); path != nil && expectString(path, "spec.rules.http.paths.path", errs) {
+		if pt == nil || pt.Value != "ImplementationSpecific" {
+			if !strings.HasPrefix(path.Value, "/") {
+				*errs = append(*errs, errAt(path, fmt.Sprintf("spec.rules.http.paths.path has invalid format '%s'", path.Value)))
+			}
+		}
+	}
+	_, backend := getMap(p, "backend")
+	if backend == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.rules.http.paths.backend is required"})
+	} else {
+		validateIngressBackend(backend, "spec.rules.http.paths.backend", errs)
+	}
+}
+
+var (
+	ingressBackendRequirements = []requirement{exactlyOneOf("service", "resource")}
+	ingressPortRequirements    = []requirement{exactlyOneOf("number", "name")}
+)
+
+// validateIngressBackend: ровно один из service и resource.
+func validateIngressBackend(b *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectType(b, yaml.MappingNode, field, errs) {
+		return
+	}
+	if !checkRequirements(b, field, ingressBackendRequirements, errs) {
+		return
+	}
+	_, svc := getMap(b, "service")
+	if _, res := getMap(b, "resource"); res != nil {
+		if expectType(res, yaml.MappingNode, field+".resource", errs) {
+			for _, f := range []string{"kind", "name"} {
+				if _, v := getMap(res, f); v == nil {
+					*errs = append(*errs, errAt(res, field+".resource."+f+" is required"))
+				}
+			}
+		}
+		return
+	}
+	if !expectType(svc, yaml.MappingNode, field+".service", errs) {
+		return
+	}
+	_, name := getMap(svc, "name")
+	if name == nil {
+		*errs = append(*errs, errAt(svc, field+".service.name is required"))
+	} else if expectString(name, field+".service.name", errs) && (len(name.Value) > 63 || !dnsLabelRegex.MatchString(name.Value)) {
+		*errs = append(*errs, errAt(name, fmt.Sprintf("%s.service.name has invalid format '%s'", field, name.Value)))
+	}
+	_, port := getMap(svc, "port")
+	if port == nil {
+		*errs = append(*errs, errAt(svc, field+".service.port is required"))
+		return
+	}
+	if !expectType(port, yaml.MappingNode, field+".service.port", errs) {
+		return
+	}
+	if !checkRequirements(port, field+".service.port", ingressPortRequirements, errs) {
+		return
+	}
+	if _, num := getMap(port, "number"); num != nil {
+		validateIntValue(num, field+".service.port.number", errs)
+	} else if _, pname := getMap(port, "name"); expectString(pname, field+".service.port.name", errs) && !isPortName(pname.Value) {
+		*errs = append(*errs, errAt(pname, fmt.Sprintf("%s.service.port.name has invalid format '%s'", field, pname.Value)))
+	}
+}
+
+func validateNetworkPolicySpec(spec *yaml.Node, errs *[]ValidationError) {
+	// podSelector (обязательное, {} выбирает все поды)
+	_, ps := getMap(spec, "podSelector")
+	if ps == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.podSelector is required"})
+	} else {
+		validateOptionalSelector(ps, "spec.podSelector", errs)
+	}
+
+	if _, pt := getMap(spec, "policyTypes"); pt != nil && expectType(pt, yaml.SequenceNode, "spec.policyTypes", errs) {
+		for _, t := range pt.Content {
+			if expectString(t, "spec.policyTypes", errs) && !contains(policyTypes, t.Value) {
+				*errs = append(*errs, errAt(t, fmt.Sprintf("spec.policyTypes has unsupported value '%s'", t.Value)))
+			}
+		}
+	}
+
+	for _, dir := range []struct{ key, peers string }{{"ingress", "from"}, {"egress", "to"}} {
+		_, rules := getMap(spec, dir.key)
+		if rules == nil || !expectType(rules, yaml.SequenceNode, "spec."+dir.key, errs) {
+			continue
+		}
+		for _, r := range rules.Content {
+			if r.Kind != yaml.MappingNode {
+				*errs = append(*errs, errAt(r, "spec."+dir.key+" must be array"))
+				continue
+			}
+			if _, peers := getMap(r, dir.peers); peers != nil {
+				validateNetworkPolicyPeers(peers, "spec."+dir.key+"."+dir.peers, errs)
+			}
+			if _, ports := getMap(r, "ports"); ports != nil {
+				validateNetworkPolicyPorts(ports, "spec."+dir.key+".ports", errs)
+			}
+		}
+	}
+}
+
+func validateOptionalSelector(sel *yaml.Node, field string, errs *[]ValidationError) {
+	if expectType(sel, yaml.MappingNode, field, errs) && len(sel.Content) > 0 {
+		validateLabelSelector(sel, field, errs)
+	}
+}
+
+// ipBlock не сочетается с селекторами; podSelector и namespaceSelector вместе допустимы
+var networkPolicyPeerRequirements = []requirement{
+	atLeastOneOf("podSelector", "namespaceSelector", "ipBlock"),
+	atMostOneOf("ipBlock", "podSelector"),
+	atMostOneOf("ipBlock", "namespaceSelector"),
+}
+
+func validateNetworkPolicyPeers(peers *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectType(peers, yaml.SequenceNode, field, errs) {
+		return
+	}
+	for _, p := range peers.Content {
+		if p.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(p, field+" must be array"))
+			continue
+		}
+		checkRequirements(p, field, networkPolicyPeerRequirements, errs)
+		if _, pod := getMap(p, "podSelector"); pod != nil {
+			validateOptionalSelector(pod, field+".podSelector", errs)
+		}
+		if _, ns := getMap(p, "namespaceSelector"); ns != nil {
+			validateOptionalSelector(ns, field+".namespaceSelector", errs)
+		}
+		_, ipb := getMap(p, "ipBlock")
+		if ipb == nil {
+			continue
+		}
+		validateIPBlock(ipb, field+".ipBlock", errs)
+	}
+}
+
+func validateIPBlock(ipb *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectType(ipb, yaml.MappingNode, field, errs) {
+		return
+	}
+	_, cidr := getMap(ipb, "cidr")
+	if cidr == nil {
+		*errs = append(*errs, errAt(ipb, field+".cidr is required"))
+		return
+	}
+	if !expectString(cidr, field+".cidr", errs) {
+		return
+	}
+	_, block, err := net.ParseCIDR(cidr.Value)
+	if err != nil {
+		*errs = append(*errs, errAt(cidr, fmt.Sprintf("%s.cidr has invalid format '%s'", field, cidr.Value)))
+		return
+	}
+	_, except := getMap(ipb, "except")
+	if except == nil || !expectType(except, yaml.SequenceNode, field+".except", errs) {
+		return
+	}
+	for _, e := range except.Content {
+		if !expectString(e, field+".except", errs) {
+			continue
+		}
+		ip, sub, err := net.ParseCIDR(e.Value)
+		if err != nil {
+			*errs = append(*errs, errAt(e, fmt.Sprintf("%s.except has invalid format '%s'", field, e.Value)))
+			continue
+		}
+		outer, _ := block.Mask.Size()
+		inner, _ := sub.Mask.Size()
+		if !block.Contains(ip) || inner <= outer {
+			*errs = append(*errs, errAt(e, fmt.Sprintf("%s.except '%s' is not inside cidr '%s'", field, e.Value, cidr.Value)))
+		}
+	}
+}
+
+func validateNetworkPolicyPorts(ports *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectType(ports, yaml.SequenceNode, field, errs) {
+		return
+	}
+	for _, p := range ports.Content {
+		if p.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(p, field+" must be array"))
+			continue
+		}
+		if _, proto := getMap(p, "protocol"); proto != nil && expectString(proto, field+".protocol", errs) {
+			if !contains(config.Protocols, strings.ToUpper(proto.Value)) {
+				*errs = append(*errs, errAt(proto, fmt.Sprintf("protocol has unsupported value '%s'", proto.Value)))
+			}
+		}
+		_, port := getMap(p, "port")
+		start := -1
+		if port != nil {
+			if num, _ := validatePortRef(port, field+".port", errs); num > 0 {
+				start = num
+			}
+		}
+		k, end := getMap(p, "endPort")
+		if end == nil {
+			continue
+		}
+		if start < 0 {
+			*errs = append(*errs, errAt(k, field+".endPort requires numeric port"))
+			continue
+		}
+		if n, ok := validateIntValue(end, field+".endPort", errs); ok && n < start {
+			*errs = append(*errs, errAt(end, fmt.Sprintf("%s.endPort %d is less than port %d", field, n, start)))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// правило находки "not a Kubernetes manifest"
+const nonKubernetesRule = "non-k8s"
+
+const composeGuess = "docker-compose file"
+
+// известные виды YAML, которые попадают в рекурсивный обход: по набору ключей верхнего уровня
+var nonKubernetesSignatures = []struct {
+	name string
+	keys []string
+}{
+	{composeGuess, []string{"services"}},
+	{"GitHub Actions workflow", []string{"on", "jobs"}},
+	{"GitLab CI config", []string{"stages"}},
+	{"Helm chart", []string{"apiVersion", "name", "version"}},
+	{"kustomization", []string{"resources"}},
+	{"OpenAPI document", []string{"openapi"}},
+}
+
+// nonKubernetes определяет файл, ни в одном документе которого нет ни apiVersion, ни kind
+// (Chart.yaml с apiVersion без kind тоже сюда относится). Возвращает false или догадку о виде файла.
+func nonKubernetes(m *manifest) (bool, string) {
+	for _, top := range m.docs {
+		if k, _ := getMap(top, "kind"); k != nil {
+			return false, ""
+		}
+		if k, _ := getMap(top, "apiVersion"); k != nil && !hasKeys(top, "name", "version") {
+			return false, ""
+		}
+	}
+	for _, sig := range nonKubernetesSignatures {
+		if hasKeys(m.docs[0], sig.keys...) {
+			return true, sig.name
+		}
+	}
+	return true, ""
+}
+
+func hasKeys(n *yaml.Node, keys ...string) bool {
+	for _, key := range keys {
+		if k, _ := getMap(n, key); k == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// checkNonKubernetes заменяет десятки находок "is required" по чужому YAML одной находкой
+// или, при skipNonKubernetes, помечает файл пропущенным. true — файл дальше не проверяется.
+func checkNonKubernetes(m *manifest) bool {
+	ok, guess := nonKubernetes(m)
+	if !ok {
+		return false
+	}
+	if guess == composeGuess && config.TranslateCompose {
+		validateCompose(m)
+		m.docs = nil
+		return true
+	}
+	if config.SkipNonKubernetes {
+		m.skipped = true
+		m.docs = nil
+		return true
+	}
+	e := errAt(m.docs[0], "not a Kubernetes manifest: no apiVersion or kind")
+	e.Rule = nonKubernetesRule
+	e.Hint = "use --skip-non-k8s to skip such files"
+	if guess != "" {
+		e.Hint = "looks like a " + guess + "; " + e.Hint
+	}
+	m.errs = append(m.errs, e)
+	m.docs = nil
+	return true
+}
@@ -0,0 +1,114 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// аннотации kustomize/kpt с исходным файлом ресурса; internal.* — с kustomize 4
+var originPathAnnotations = []string{"internal.config.kubernetes.io/path", "config.kubernetes.io/path"}
+
+// originEntry — участок сгенерированного YAML: строки с Line и до следующего участка
+// взяты из File начиная с SourceLine
+type originEntry struct {
+	Line       int    `json:"line"`
+	File       string `json:"file"`
+	SourceLine int    `json:"sourceLine"`
+}
+
+// originMap — карта строк сгенерированного ввода (--origin-map), по возрастанию Line
+var originMap []originEntry
+
+// loadOriginMap читает карту происхождения: JSON-массив {line, file, sourceLine}.
+func loadOriginMap(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var entries []originEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	for i := range entries {
+		e := &entries[i]
+		if e.Line < 1 || e.File == "" {
+			return fmt.Errorf("entry %d: line and file are required", i+1)
+		}
+		if e.SourceLine < 1 {
+			e.SourceLine = 1
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Line < entries[j].Line })
+	originMap = entries
+	return nil
+}
+
+// applyOrigins переносит находки сгенерированного YAML на исходные файлы: по --origin-map,
+// а без неё — по аннотации config.kubernetes.io/path документа. Во втором случае строка
+// отсчитывается от начала документа, что точно, пока генератор не меняет ресурс построчно.
+func applyOrigins(m *manifest) {
+	if len(originMap) == 0 && !hasOriginAnnotations(m.docs) {
+		return
+	}
+	for i := range m.errs {
+		e := &m.errs[i]
+		if e.Line == 0 {
+			continue
+		}
+		file, line := originOf(m.docs, e.Line)
+		if file == "" {
+			continue
+		}
+		delta := line - e.Line
+		e.File, e.Line = file, line
+		if e.EndLine > 0 {
+			e.EndLine += delta
+		}
+	}
+}
+
+// originOf — исходные файл и строка для строки line сгенерированного ввода.
+func originOf(docs []*yaml.Node, line int) (string, int) {
+	if i := sort.Search(len(originMap), func(i int) bool { return originMap[i].Line > line }); i > 0 {
+		o := originMap[i-1]
+		return o.File, o.SourceLine + line - o.Line
+	}
+	// документ, в котором лежит строка: последний, начавшийся не позже неё
+	var doc *yaml.Node
+	for _, top := range docs {
+		if top.Line <= line {
+			doc = top
+		}
+	}
+	if path := originAnnotation(doc); path != "" {
+		return path, line - doc.Line + 1
+	}
+	return "", 0
+}
+
+func hasOriginAnnotations(docs []*yaml.Node) bool {
+	for _, top := range docs {
+		if originAnnotation(top) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func originAnnotation(top *yaml.Node) string {
+	if top == nil {
+		return ""
+	}
+	_, meta := getMap(top, "metadata")
+	_, ann := getMap(meta, "annotations")
+	for _, key := range originPathAnnotations {
+		if _, v := getMap(ann, key); v != nil && v.Kind == yaml.ScalarNode && v.Value != "" {
+			return v.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"runtime"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// с какого числа контейнеров в поде они проверяются параллельно:
+// на обычных подах запуск горутин дороже самих проверок
+const parallelContainersMin = 32
+
+// validateContainers проверяет контейнеры пода. Поддеревья контейнеров независимы,
+// поэтому в больших подах (сгенерированные Job с сотнями контейнеров) они проверяются
+// параллельно; находки сливаются в порядке контейнеров, вывод не отличается от
+// последовательной проверки. С --timings замеры общие, проверка идёт последовательно.
+func validateContainers(items []*yaml.Node, check func(c *yaml.Node, errs *[]ValidationError), errs *[]ValidationError) {
+	if len(items) < parallelContainersMin || ruleTimings != nil {
+		for _, c := range items {
+			check(c, errs)
+		}
+		return
+	}
+	found := make([][]ValidationError, len(items))
+	panics := make([]any, len(items))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				checkRecovered(items[i], check, &found[i], &panics[i])
+			}
+		}()
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	for i := range items {
+		// паника в горутине не дошла бы до recover документа (validateIsolated)
+		if panics[i] != nil {
+			panic(panics[i])
+		}
+		*errs = append(*errs, found[i]...)
+	}
+}
+
+func checkRecovered(c *yaml.Node, check func(c *yaml.Node, errs *[]ValidationError), errs *[]ValidationError, p *any) {
+	defer func() { *p = recover() }()
+	check(c, errs)
+}
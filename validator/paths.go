@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pathStep — шаг пути политики: ключ mapping'а ("*" — любой ключ),
+// затем, если задан, индекс списка (-1 — любой элемент).
+type pathStep struct {
+	key   string
+	list  bool
+	index int
+}
+
+// parsePath разбирает путь вида spec.containers[*].securityContext.privileged.
+func parsePath(path string) ([]pathStep, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path must not be empty")
+	}
+	var steps []pathStep
+	for _, part := range strings.Split(path, ".") {
+		s := pathStep{key: part}
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("path has invalid format '%s'", path)
+			}
+			s.key, s.list = part[:i], true
+			idx := part[i+1 : len(part)-1]
+			if idx == "*" {
+				s.index = -1
+			} else if n, err := strconv.Atoi(idx); err == nil && n >= 0 {
+				s.index = n
+			} else {
+				return nil, fmt.Errorf("path has invalid format '%s'", path)
+			}
+		}
+		if s.key == "" {
+			return nil, fmt.Errorf("path has invalid format '%s'", path)
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+// pathMatch — найденный по пути узел: ключ последнего mapping'а (для элемента списка — сам элемент)
+// и значение.
+type pathMatch struct {
+	key, value *yaml.Node
+}
+
+// matchPath возвращает все узлы дерева n, совпадающие с путём.
+func matchPath(n *yaml.Node, steps []pathStep) []pathMatch {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	s := steps[0]
+	var matches []pathMatch
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if s.key != "*" && k.Value != s.key {
+			continue
+		}
+		var found []pathMatch
+		if !s.list {
+			found = []pathMatch{{k, v}}
+		} else if v.Kind == yaml.SequenceNode {
+			for j, item := range v.Content {
+				if s.index < 0 || s.index == j {
+					found = append(found, pathMatch{item, item})
+				}
+			}
+		}
+		for _, m := range found {
+			if len(steps) == 1 {
+				matches = append(matches, m)
+			} else {
+				matches = append(matches, matchPath(m.value, steps[1:])...)
+			}
+		}
+	}
+	return matches
+}
@@ -0,0 +1,497 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func validatePodSpec(spec *yaml.Node, errs *[]ValidationError) {
+	applyRules(spec, scopePodSpec, "", errs)
+
+	// os (необязательное; допустимые значения задаются правилами)
+	var osName string
+	if _, osNode := getMap(spec, "os"); osNode != nil {
+		switch osNode.Kind {
+		case yaml.ScalarNode:
+			osName = strings.ToLower(osNode.Value)
+		case yaml.MappingNode:
+			_, name := getMap(osNode, "name")
+			if name == nil {
+				*errs = append(*errs, ValidationError{Msg: "spec.os.name is required"})
+			} else if expectType(name, yaml.ScalarNode, "spec.os.name", errs) {
+				osName = strings.ToLower(name.Value)
+			}
+		default:
+			*errs = append(*errs, errAt(osNode, "spec.os must be object"))
+		}
+	}
+	if osName == "linux" || osName == "windows" {
+		validateOSFields(spec, osName, errs)
+	}
+
+	// тома и claim'ы пода, на которые ссылаются контейнеры
+	vols := podVolumes(spec)
+	claims := validatePodResourceClaims(spec, errs)
+
+	// containers (обязательное)
+	_, conts := getMap(spec, "containers")
+	if conts == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.containers is required"})
+	} else if expectNonEmpty(conts, yaml.SequenceNode, "spec.containers", errs) {
+		validateContainers(conts.Content, func(item *yaml.Node, errs *[]ValidationError) {
+			if item.Kind != yaml.MappingNode {
+				*errs = append(*errs, errAt(item, "spec.containers must be array"))
+				return
+			}
+			validateContainer(item, errs)
+			validateVolumeMounts(item, vols, errs)
+			validateContainerClaims(item, claims, errs)
+		}, errs)
+		// уникальность имён — по всем контейнерам, после их проверки
+		seen := map[string]struct{}{}
+		for _, item := range conts.Content {
+			if _, n := getMap(item, "name"); item.Kind == yaml.MappingNode && n != nil && n.Kind == yaml.ScalarNode {
+				if _, ok := seen[n.Value]; ok {
+					*errs = append(*errs, errAt(n, fmt.Sprintf("containers.name has invalid format '%s'", n.Value)))
+				}
+				seen[n.Value] = struct{}{}
+			}
+		}
+	}
+
+	if conts != nil && conts.Kind == yaml.SequenceNode {
+		// hostPort не должны пересекаться между контейнерами пода
+		validateHostPortConflicts(conts, errs)
+		validateImagePlatforms(spec, conts, errs)
+		validateGPU(spec, conts, errs)
+	}
+
+	validatePodLimits(spec, errs)
+	validateIntFields(spec, "spec.", errs, "terminationGracePeriodSeconds", "activeDeadlineSeconds")
+
+	// securityContext (необязательное)
+	if _, sc := getMap(spec, "securityContext"); sc != nil {
+		validatePodSecurityContext(sc, errs)
+	}
+
+	// overhead (необязательное)
+	if _, oh := getMap(spec, "overhead"); oh != nil {
+		validateOverhead(oh, errs)
+	}
+
+	// volumes (необязательное)
+	if _, vols := getMap(spec, "volumes"); vols != nil {
+		validateVolumes(vols, errs)
+	}
+
+	// hostAliases (необязательное)
+	if _, ha := getMap(spec, "hostAliases"); ha != nil {
+		validateHostAliases(ha, errs)
+	}
+
+	// dnsConfig (необязательное)
+	if _, dns := getMap(spec, "dnsConfig"); dns != nil {
+		validateDNSConfig(dns, errs)
+	}
+
+	// priorityClassName (необязательное)
+	if _, pc := getMap(spec, "priorityClassName"); pc != nil {
+		validatePriorityClassName(pc, errs)
+	}
+
+	// preemptionPolicy (необязательное)
+	if _, pp := getMap(spec, "preemptionPolicy"); pp != nil {
+		validatePreemptionPolicy(pp, errs)
+	}
+
+	// serviceAccountName (необязательное)
+	if _, sa := getMap(spec, "serviceAccountName"); sa != nil {
+		validateDNSName(sa, "spec.serviceAccountName", errs)
+	}
+
+	// runtimeClassName (необязательное)
+	if _, rc := getMap(spec, "runtimeClassName"); rc != nil {
+		validateRuntimeClassName(rc, errs)
+	}
+
+	// schedulerName (необязательное)
+	if _, sn := getMap(spec, "schedulerName"); sn != nil {
+		validateSchedulerName(sn, errs)
+	}
+}
+
+var (
+	// поля, которые API-сервер запрещает для подов с os.name: windows
+	linuxOnlyPodFields               = []string{"hostPID", "hostIPC", "hostNetwork", "shareProcessNamespace"}
+	linuxOnlyPodSecurityFields       = []string{"seLinuxOptions", "seccompProfile", "appArmorProfile", "fsGroup", "fsGroupChangePolicy", "sysctls", "runAsUser", "runAsGroup", "supplementalGroups"}
+	linuxOnlyContainerSecurityFields = []string{"seLinuxOptions", "seccompProfile", "appArmorProfile", "capabilities", "readOnlyRootFilesystem", "privileged", "allowPrivilegeEscalation", "procMount", "runAsUser", "runAsGroup"}
+	// и наоборот, для os.name: linux
+	windowsOnlySecurityFields = []string{"windowsOptions"}
+)
+
+func validateOSFields(spec *yaml.Node, osName string, errs *[]ValidationError) {
+	podFields, podSecFields, contSecFields := []string(nil), windowsOnlySecurityFields, windowsOnlySecurityFields
+	if osName == "windows" {
+		podFields, podSecFields, contSecFields = linuxOnlyPodFields, linuxOnlyPodSecurityFields, linuxOnlyContainerSecurityFields
+	}
+	forbid := func(m *yaml.Node, fields []string, prefix string) {
+		for _, f := range fields {
+			if k, _ := getMap(m, f); k != nil {
+				*errs = append(*errs, errAt(k, fmt.Sprintf("%s%s is not allowed when os is '%s'", prefix, f, osName)))
+			}
+		}
+	}
+
+	forbid(spec, podFields, "spec.")
+	if _, sc := getMap(spec, "securityContext"); sc != nil {
+		forbid(sc, podSecFields, "spec.securityContext.")
+	}
+	for _, list := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		_, conts := getMap(spec, list)
+		if conts == nil || conts.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, c := range conts.Content {
+			if _, sc := getMap(c, "securityContext"); sc != nil {
+				forbid(sc, contSecFields, list+".securityContext.")
+			}
+		}
+	}
+}
+
+var (
+	memoryRegex = regexp.MustCompile(`^[0-9]+(Gi|Mi|Ki)$`)
+	portMin     = 1
+	portMax     = 65535
+)
+
+func validateContainer(c *yaml.Node, errs *[]ValidationError) {
+	// форматы name и image задаются правилами
+	applyRules(c, scopeContainer, "", errs)
+
+	// name (обязательное)
+	_, name := getMap(c, "name")
+	if name == nil {
+		*errs = append(*errs, ValidationError{Msg: "name is required", node: c})
+	} else if expectType(name, yaml.ScalarNode, "name", errs) {
+		if strings.TrimSpace(name.Value) == "" {
+			*errs = append(*errs, errAt(name, "name is required"))
+		}
+	}
+
+	// image (обязательное)
+	_, image := getMap(c, "image")
+	if image == nil {
+		*errs = append(*errs, ValidationError{Msg: "containers.image is required", node: c})
+	} else {
+		expectString(image, "containers.image", errs)
+	}
+
+	// workingDir (необязательное)
+	if _, wd := getMap(c, "workingDir"); wd != nil {
+		if expectString(wd, "containers.workingDir", errs) && !strings.HasPrefix(wd.Value, "/") {
+			*errs = append(*errs, errAt(wd, fmt.Sprintf("containers.workingDir has invalid format '%s'", wd.Value)))
+		}
+	}
+
+	// command/args (необязательные)
+	_, command := getMap(c, "command")
+	if command != nil {
+		validateStringList(command, "containers.command", errs)
+	}
+	if _, args := getMap(c, "args"); args != nil {
+		validateStringList(args, "containers.args", errs)
+	}
+	if command != nil && image != nil && image.Kind == yaml.ScalarNode {
+		validateEntrypointOverride(command, image.Value, errs)
+	}
+
+	// env (необязательное)
+	if _, env := getMap(c, "env"); env != nil {
+		validateEnv(env, errs)
+	}
+
+	// секреты в env (по --detect-secrets)
+	if config.DetectSecrets {
+		validateEnvSecrets(c, errs)
+	}
+
+	// ports (необязательное)
+	if _, ports := getMap(c, "ports"); ports != nil {
+		if expectType(ports, yaml.SequenceNode, "containers.ports", errs) {
+			for _, p := range ports.Content {
+				if p.Kind != yaml.MappingNode {
+					*errs = append(*errs, errAt(p, "containers.ports must be array"))
+					continue
+				}
+				validateContainerPort(p, errs)
+			}
+		}
+	}
+
+	// securityContext (необязательное)
+	if _, sc := getMap(c, "securityContext"); sc != nil {
+		validateContainerSecurityContext(sc, errs)
+	}
+	validateCapabilities(c, errs)
+
+	// readinessProbe (необязательное)
+	if _, rp := getMap(c, "readinessProbe"); rp != nil {
+		validateProbe(c, rp, errs, "containers.readinessProbe")
+	}
+
+	// livenessProbe (необязательное)
+	if _, lp := getMap(c, "livenessProbe"); lp != nil {
+		validateProbe(c, lp, errs, "containers.livenessProbe")
+	}
+	validateProbeConsistency(c, errs)
+
+	// resources (обязательное)
+	_, res := getMap(c, "resources")
+	if res == nil {
+		*errs = append(*errs, ValidationError{Msg: "containers.resources is required", node: c})
+	} else if expectType(res, yaml.MappingNode, "containers.resources", errs) {
+		validateResources(res, errs)
+	}
+}
+
+// validateStringList проверяет command/args: частая ошибка — строка вида "sh -c ..." вместо списка.
+func validateStringList(n *yaml.Node, field string, errs *[]ValidationError) {
+	if n.Kind == yaml.ScalarNode && strings.ContainsAny(strings.TrimSpace(n.Value), " \t") {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s must be list, not a single string '%s'", field, n.Value)))
+		return
+	}
+	if !expectType(n, yaml.SequenceNode, field, errs) {
+		return
+	}
+	for _, item := range n.Content {
+		if item.Kind != yaml.ScalarNode {
+			*errs = append(*errs, errAt(item, field+" must be array of strings"))
+		}
+	}
+}
+
+// validateEntrypointOverride предупреждает, если command подменяет entrypoint, заданный
+// в конфиге для префикса образа (imageEntrypoints).
+func validateEntrypointOverride(command *yaml.Node, image string, errs *[]ValidationError) {
+	// берём самый длинный подходящий префикс
+	match := ""
+	for prefix := range config.ImageEntrypoints {
+		if strings.HasPrefix(image, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	if match == "" {
+		return
+	}
+	entrypoint := config.ImageEntrypoints[match]
+	var got []string
+	if command.Kind == yaml.SequenceNode {
+		for _, item := range command.Content {
+			got = append(got, item.Value)
+		}
+	}
+	if strings.Join(got, "\x00") != strings.Join(entrypoint, "\x00") {
+		*errs = append(*errs, warnAt(command, fmt.Sprintf("containers.command overrides entrypoint of '%s' (%s)", image, strings.Join(entrypoint, " "))))
+	}
+}
+
+func validateContainerPort(p *yaml.Node, errs *[]ValidationError) {
+	_, cport := getMap(p, "containerPort")
+	if cport == nil {
+		*errs = append(*errs, ValidationError{Msg: "containers.ports.containerPort is required", node: p})
+	} else {
+		validateIntValue(cport, "containerPort", errs)
+	}
+
+	// hostPort/hostIP (необязательные)
+	if _, hport := getMap(p, "hostPort"); hport != nil {
+		if val, ok := validateIntValue(hport, "hostPort", errs); ok && val != 0 && activeProfile() == profileRestricted {
+			*errs = append(*errs, restrictedAt(hport, "hostPort should not be used under restricted profile"))
+		}
+	}
+	if _, hip := getMap(p, "hostIP"); hip != nil {
+		validateIP(hip, "containers.ports.hostIP", errs)
+	}
+
+	if _, proto := getMap(p, "protocol"); proto != nil {
+		if !expectString(proto, "protocol", errs) {
+			return
+		}
+		if !contains(config.Protocols, strings.ToUpper(proto.Value)) {
+			*errs = append(*errs, errAt(proto, fmt.Sprintf("protocol has unsupported value '%s'", proto.Value)))
+		}
+	}
+}
+
+var (
+	envRequirements       = []requirement{atMostOneOf("value", "valueFrom")}
+	envSourceRequirements = []requirement{exactlyOneOf("fieldRef", "resourceFieldRef", "configMapKeyRef", "secretKeyRef")}
+	probeActions          = []string{"httpGet", "exec", "tcpSocket", "grpc"}
+	probeRequirements     = []requirement{exactlyOneOf(probeActions...)}
+)
+
+func validateEnv(env *yaml.Node, errs *[]ValidationError) {
+	if !expectType(env, yaml.SequenceNode, "containers.env", errs) {
+		return
+	}
+	for _, e := range env.Content {
+		if e.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(e, "containers.env must be array"))
+			continue
+		}
+		if _, name := getMap(e, "name"); name == nil {
+			*errs = append(*errs, errAt(e, "containers.env.name is required"))
+		} else {
+			expectString(name, "containers.env.name", errs)
+		}
+		if !checkRequirements(e, "containers.env", envRequirements, errs) {
+			continue
+		}
+		if _, val := getMap(e, "value"); val != nil {
+			expectType(val, yaml.ScalarNode, "containers.env.value", errs)
+		}
+		_, from := getMap(e, "valueFrom")
+		if from == nil || !expectType(from, yaml.MappingNode, "containers.env.valueFrom", errs) ||
+			!checkRequirements(from, "containers.env.valueFrom", envSourceRequirements, errs) {
+			continue
+		}
+		for _, ref := range []string{"configMapKeyRef", "secretKeyRef"} {
+			_, r := getMap(from, ref)
+			if r == nil || !expectType(r, yaml.MappingNode, "containers.env.valueFrom."+ref, errs) {
+				continue
+			}
+			if _, key := getMap(r, "key"); key == nil {
+				*errs = append(*errs, errAt(r, "containers.env.valueFrom."+ref+".key is required"))
+			} else {
+				expectString(key, "containers.env.valueFrom."+ref+".key", errs)
+			}
+		}
+	}
+}
+
+// validateProbe проверяет пробу n контейнера c; именованные порты ищутся в ports контейнера.
+func validateProbe(c, n *yaml.Node, errs *[]ValidationError, field string) {
+	if !expectType(n, yaml.MappingNode, field, errs) {
+		return
+	}
+	// без действия — прежнее сообщение о httpGet (без номера строки)
+	present := false
+	for _, a := range probeActions {
+		if k, _ := getMap(n, a); k != nil {
+			present = true
+		}
+	}
+	if !present {
+		*errs = append(*errs, ValidationError{Msg: field + ".httpGet is required", node: n})
+		return
+	}
+	if !checkRequirements(n, field, probeRequirements, errs) {
+		return
+	}
+	validateIntFields(n, field+".", errs, "initialDelaySeconds", "periodSeconds", "timeoutSeconds", "successThreshold", "failureThreshold", "terminationGracePeriodSeconds")
+	if _, exec := getMap(n, "exec"); exec != nil {
+		if expectType(exec, yaml.MappingNode, field+".exec", errs) {
+			if _, cmd := getMap(exec, "command"); cmd == nil {
+				*errs = append(*errs, errAt(exec, field+".exec.command is required"))
+			} else if validateStringList(cmd, field+".exec.command", errs); cmd.Kind == yaml.SequenceNode && len(cmd.Content) == 0 {
+				*errs = append(*errs, errAt(cmd, field+".exec.command must not be empty"))
+			}
+		}
+		return
+	}
+	for _, a := range []string{"tcpSocket", "grpc"} {
+		_, action := getMap(n, a)
+		if action == nil {
+			continue
+		}
+		if !expectType(action, yaml.MappingNode, field+"."+a, errs) {
+			return
+		}
+		// tcpSocket допускает именованный порт контейнера, grpc — только число
+		if _, port := getMap(action, "port"); port == nil {
+			*errs = append(*errs, errAt(action, field+"."+a+".port is required"))
+		} else if a == "grpc" {
+			validateIntValue(port, field+"."+a+".port", errs)
+		} else if _, name := validatePortRef(port, field+"."+a+".port", errs); name != "" {
+			validateNamedPort(c, port, field+"."+a+".port", errs)
+		}
+		return
+	}
+
+	before := len(*errs)
+	_, httpGet := getMap(n, "httpGet")
+	if !expectType(httpGet, yaml.MappingNode, field+".httpGet", errs) {
+		return
+	}
+
+	_, path := getMap(httpGet, "path")
+	if path == nil {
+		*errs = append(*errs, ValidationError{Msg: field + ".httpGet.path is required", node: httpGet})
+	} else if expectString(path, field+".httpGet.path", errs) && !strings.HasPrefix(path.Value, "/") {
+		*errs = append(*errs, errAt(path, fmt.Sprintf("%s has invalid format '%s'", field+".httpGet.path", path.Value)))
+	}
+
+	_, port := getMap(httpGet, "port")
+	if port == nil {
+		*errs = append(*errs, ValidationError{Msg: field + ".httpGet.port is required", node: httpGet})
+		return
+	}
+	if _, name := validatePortRef(port, field+".httpGet.port", errs); name != "" {
+		validateNamedPort(c, port, field+".httpGet.port", errs)
+	}
+	// запросы шлются только для пробы без ошибок в самом манифесте
+	if probeBaseURL != nil && len(*errs) == before {
+		preflightProbe(c, n, httpGet, field, errs)
+	}
+}
+
+// validateNamedPort проверяет, что порт с именем port объявлен в ports[].name контейнера c;
+// подсказка указывает на объявленные порты.
+func validateNamedPort(c, port *yaml.Node, field string, errs *[]ValidationError) {
+	_, ports := getMap(c, "ports")
+	if ports != nil && ports.Kind == yaml.SequenceNode {
+		for _, p := range ports.Content {
+			if _, name := getMap(p, "name"); name != nil && name.Value == port.Value {
+				return
+			}
+		}
+	}
+	e := errAt(port, fmt.Sprintf("%s '%s' does not match any containers.ports.name", field, port.Value))
+	if ports != nil {
+		e.Hint = fmt.Sprintf("ports are declared at line %d", ports.Line)
+	} else {
+		e.Hint = "the container declares no ports"
+	}
+	*errs = append(*errs, e)
+}
+
+func validateResources(n *yaml.Node, errs *[]ValidationError) {
+	if _, limits := getMap(n, "limits"); limits != nil {
+		validateResObj(limits, "containers.resources.limits", errs)
+	}
+	if _, req := getMap(n, "requests"); req != nil {
+		validateResObj(req, "containers.resources.requests", errs)
+	}
+}
+
+func validateResObj(n *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectType(n, yaml.MappingNode, field, errs) {
+		return
+	}
+	if _, cpu := getMap(n, "cpu"); cpu != nil {
+		if cpu.Kind != yaml.ScalarNode || cpu.Tag != "!!int" {
+			*errs = append(*errs, errAt(cpu, "cpu must be int"))
+		}
+	}
+	if _, mem := getMap(n, "memory"); mem != nil {
+		if mem.Kind != yaml.ScalarNode {
+			*errs = append(*errs, errAt(mem, "memory must be string"))
+		} else if !memoryRegex.MatchString(mem.Value) {
+			*errs = append(*errs, errAt(mem, fmt.Sprintf("memory has invalid format '%s'", mem.Value)))
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package validator
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resolveRanges дополняет ошибки концом диапазона (EndLine/EndColumn) по исходному тексту,
+// чтобы многострочные значения можно было подсветить целиком, и вычисляет позиции исправлений.
+func resolveRanges(src []byte, errs []ValidationError) {
+	lines := strings.Split(string(src), "\n")
+	for i := range errs {
+		if f := errs[i].Fix; f != nil && f.node != nil {
+			resolveFix(lines, f)
+		}
+		n := errs[i].node
+		if n == nil || errs[i].Line == 0 {
+			continue
+		}
+		// у | и > скаляров находка начинается с первой строки содержимого, а не с заголовка
+		if n.Kind == yaml.ScalarNode && n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 && errs[i].Line == n.Line {
+			errs[i].Line, errs[i].Column = blockScalarStart(lines, n)
+		}
+		errs[i].EndLine, errs[i].EndColumn = nodeEnd(lines, n)
+	}
+}
+
+// nodeEnd возвращает позицию сразу за последним символом узла; строки и колонки считаются с 1.
+func nodeEnd(lines []string, n *yaml.Node) (int, int) {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.MappingNode, yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			// пустые {} и []
+			return n.Line, n.Column + 2
+		}
+		line, col := nodeEnd(lines, n.Content[len(n.Content)-1])
+		if n.Style&yaml.FlowStyle != 0 {
+			return closingBracket(lines, line, col)
+		}
+		return line, col
+	case yaml.AliasNode:
+		return n.Line, n.Column + 1 + utf8.RuneCountInString(n.Value)
+	}
+
+	switch {
+	case n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return blockScalarEnd(lines, n)
+	case n.Style&yaml.DoubleQuotedStyle != 0:
+		return quotedEnd(lines, n, '"')
+	case n.Style&yaml.SingleQuotedStyle != 0:
+		return quotedEnd(lines, n, '\'')
+	}
+	return plainEnd(lines, n)
+}
+
+func sourceLine(lines []string, line int) []rune {
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+	return []rune(strings.TrimRight(lines[line-1], "\r"))
+}
+
+func indentOf(l []rune) int {
+	i := 0
+	for i < len(l) && l[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func isBlank(l []rune) bool {
+	return strings.TrimSpace(string(l)) == ""
+}
+
+// blockScalarStart — первая непустая строка содержимого | или > скаляра.
+func blockScalarStart(lines []string, n *yaml.Node) (int, int) {
+	for i := n.Line + 1; i <= len(lines); i++ {
+		if l := sourceLine(lines, i); !isBlank(l) {
+			return i, indentOf(l) + 1
+		}
+	}
+	return n.Line, n.Column
+}
+
+// blockScalarEnd ищет последнюю непустую строку содержимого | или > скаляра:
+// отступ содержимого задаёт первая непустая строка после заголовка.
+func blockScalarEnd(lines []string, n *yaml.Node) (int, int) {
+	head := sourceLine(lines, n.Line)
+	endLine, endCol := n.Line, n.Column
+	for i := n.Column - 1; i < len(head) && head[i] != ' ' && head[i] != '#'; i++ {
+		endCol = i + 2
+	}
+
+	contentIndent := -1
+	for ln := n.Line + 1; ln <= len(lines); ln++ {
+		l := sourceLine(lines, ln)
+		if isBlank(l) {
+			continue
+		}
+		ind := indentOf(l)
+		if contentIndent < 0 {
+			if ind <= indentOf(head) {
+				break
+			}
+			contentIndent = ind
+		}
+		if ind < contentIndent {
+			break
+		}
+		endLine, endCol = ln, len([]rune(strings.TrimRight(string(l), " \t")))+1
+	}
+	return endLine, endCol
+}
+
+// quotedEnd находит закрывающую кавычку с учётом экранирования: обратной косой черты и удвоенной одинарной кавычки.
+func quotedEnd(lines []string, n *yaml.Node, q rune) (int, int) {
+	ln, i := n.Line, n.Column // i указывает на символ после открывающей кавычки
+	for ln <= len(lines) {
+		l := sourceLine(lines, ln)
+		for ; i < len(l); i++ {
+			switch {
+			case q == '"' && l[i] == '\\':
+				i++
+			case l[i] == q && q == '\'' && i+1 < len(l) && l[i+1] == '\'':
+				i++
+			case l[i] == q:
+				return ln, i + 2
+			}
+		}
+		ln, i = ln+1, 0
+	}
+	return n.Line, n.Column + utf8.RuneCountInString(n.Value) + 2
+}
+
+// plainEnd сопоставляет значение скаляра со строками исходника: многострочный
+// plain-скаляр свёрнут парсером через пробелы, поэтому продолжения снимаются по одному.
+func plainEnd(lines []string, n *yaml.Node) (int, int) {
+	l := sourceLine(lines, n.Line)
+	width := utf8.RuneCountInString(n.Value)
+	if n.Column-1+width <= len(l) && string(l[n.Column-1:n.Column-1+width]) == n.Value {
+		return n.Line, n.Column + width
+	}
+
+	rest := n.Value
+	var first string
+	if n.Column-1 < len(l) {
+		first = strings.TrimSpace(string(l[n.Column-1:]))
+	}
+	if !strings.HasPrefix(rest, first) {
+		return n.Line, n.Column + width
+	}
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, first), " \n")
+	for ln := n.Line + 1; ln <= len(lines) && rest != ""; ln++ {
+		l = sourceLine(lines, ln)
+		t := strings.TrimSpace(string(l))
+		if t == "" {
+			continue
+		}
+		if !strings.HasPrefix(rest, t) {
+			break
+		}
+		rest = strings.TrimLeft(strings.TrimPrefix(rest, t), " \n")
+		if rest == "" {
+			return ln, len([]rune(strings.TrimRight(string(l), " \t"))) + 1
+		}
+	}
+	return n.Line, n.Column + width
+}
+
+// closingBracket пропускает пробелы, запятые и комментарии до ] или } flow-коллекции.
+func closingBracket(lines []string, line, col int) (int, int) {
+	for ln, i := line, col-1; ln <= len(lines); ln, i = ln+1, 0 {
+		l := sourceLine(lines, ln)
+		for ; i < len(l); i++ {
+			switch l[i] {
+			case ']', '}':
+				return ln, i + 2
+			case ' ', '\t', ',':
+			case '#':
+				i = len(l)
+			default:
+				return line, col
+			}
+		}
+	}
+	return line, col
+}
@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// probeBaseURL — адрес локально запущенного экземпляра, на который --probe-preflight
+// отправляет запросы httpGet-проб; nil — проверка выключена
+var probeBaseURL *url.URL
+
+// parseProbeBaseURL разбирает --base-url: нужны схема http(s) и хост.
+func parseProbeBaseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("base-url '%s' must be an http or https URL with a host", s)
+	}
+	return u, nil
+}
+
+// preflightProbe выполняет запрос httpGet-пробы h контейнера c к probeBaseURL, как kubelet:
+// путь, заголовки и тайм-аут из пробы, успех — ответ 2xx или 3xx без перехода по
+// редиректу. Порт берётся из base-url, если он там указан (port-forward одного порта),
+// иначе из пробы.
+func preflightProbe(c, n, h *yaml.Node, field string, errs *[]ValidationError) {
+	_, path := getMap(h, "path")
+	_, port := getMap(h, "port")
+	target := *probeBaseURL
+	target.Path, target.RawQuery = "", ""
+	if target.Port() == "" {
+		num := port.Value
+		if port.Tag == "!!str" {
+			if num = namedContainerPort(c, port.Value); num == "" {
+				return
+			}
+		}
+		target.Host = net.JoinHostPort(target.Hostname(), num)
+	}
+	ref, err := url.Parse(path.Value)
+	if err != nil {
+		*errs = append(*errs, errAt(path, fmt.Sprintf("%s.httpGet.path has invalid format '%s'", field, path.Value)))
+		return
+	}
+	u := target.ResolveReference(ref).String()
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		*errs = append(*errs, errAt(path, fmt.Sprintf("%s.httpGet cannot request %s: %v", field, u, err)))
+		return
+	}
+	if _, hs := getMap(h, "httpHeaders"); hs != nil && hs.Kind == yaml.SequenceNode {
+		for _, hdr := range hs.Content {
+			_, name := getMap(hdr, "name")
+			_, value := getMap(hdr, "value")
+			if name == nil || value == nil {
+				continue
+			}
+			if strings.EqualFold(name.Value, "Host") {
+				req.Host = value.Value
+				continue
+			}
+			req.Header.Add(name.Value, value.Value)
+		}
+	}
+	timeout := time.Second
+	if _, t := getMap(n, "timeoutSeconds"); t != nil {
+		if s, err := strconv.Atoi(t.Value); err == nil && s > 0 {
+			timeout = time.Duration(s) * time.Second
+		}
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		*errs = append(*errs, errAt(path, fmt.Sprintf("%s.httpGet %s is unreachable: %v", field, u, err)))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		*errs = append(*errs, errAt(path, fmt.Sprintf("%s.httpGet %s returned %s", field, u, resp.Status)))
+	}
+}
+
+// namedContainerPort — номер порта контейнера c с именем name или "".
+func namedContainerPort(c *yaml.Node, name string) string {
+	_, ports := getMap(c, "ports")
+	if ports == nil || ports.Kind != yaml.SequenceNode {
+		return ""
+	}
+	for _, p := range ports.Content {
+		if _, n := getMap(p, "name"); n != nil && n.Value == name {
+			if _, num := getMap(p, "containerPort"); num != nil {
+				return num.Value
+			}
+		}
+	}
+	return ""
+}
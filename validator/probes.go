@@ -0,0 +1,46 @@
+package validator
+
+import "gopkg.in/yaml.v3"
+
+const (
+	probesIdenticalRule        = "containers.probes.identical"
+	probesMissingReadinessRule = "containers.probes.missing-readiness"
+)
+
+// validateProbeConsistency — проверки пар проб контейнера (--lint-probes): одинаковые
+// livenessProbe и readinessProbe перезапускают под при первой же перегрузке, а
+// livenessProbe без readinessProbe пускает трафик в ещё не готовый контейнер.
+func validateProbeConsistency(c *yaml.Node, errs *[]ValidationError) {
+	if !config.LintProbes {
+		return
+	}
+	lk, lp := getMap(c, "livenessProbe")
+	if lp == nil {
+		return
+	}
+	_, rp := getMap(c, "readinessProbe")
+	if rp == nil {
+		e := warnAt(lk, "containers.livenessProbe is set without readinessProbe")
+		e.Rule = probesMissingReadinessRule
+		*errs = append(*errs, e)
+		return
+	}
+	if sameYAML(lp, rp) {
+		e := warnAt(lk, "containers.livenessProbe is identical to readinessProbe; a failing check restarts the container instead of only taking it out of service")
+		e.Rule = probesIdenticalRule
+		*errs = append(*errs, e)
+	}
+}
+
+// sameYAML — узлы совпадают по содержимому; стиль записи, позиции и комментарии не учитываются.
+func sameYAML(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Value != b.Value || a.ShortTag() != b.ShortTag() || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !sameYAML(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// intRange — допустимые значения целого поля; max < 0 — без верхней границы
+type intRange struct{ min, max int }
+
+// intFieldRanges — диапазоны целых полей по имени поля. Одно имя в разных объектах
+// (port, replicas, timeoutSeconds) везде означает одно и то же, поэтому таблица общая.
+var intFieldRanges = map[string]intRange{
+	// порты
+	"port":          {portMin, portMax},
+	"targetPort":    {portMin, portMax},
+	"containerPort": {portMin, portMax},
+	"nodePort":      {portMin, portMax},
+	"number":        {portMin, portMax},
+	"endPort":       {portMin, portMax},
+	"hostPort":      {0, portMax},
+
+	// реплики и история контроллеров
+	"replicas":                   {0, -1},
+	"minReplicas":                {1, -1},
+	"maxReplicas":                {1, -1},
+	"revisionHistoryLimit":       {0, -1},
+	"partition":                  {0, -1},
+	"successfulJobsHistoryLimit": {0, -1},
+	"failedJobsHistoryLimit":     {0, -1},
+
+	// Job
+	"backoffLimit":         {0, -1},
+	"backoffLimitPerIndex": {0, -1},
+	"maxFailedIndexes":     {0, -1},
+	"completions":          {0, -1},
+	"parallelism":          {0, -1},
+
+	// сроки, секунды
+	"minReadySeconds":               {0, -1},
+	"progressDeadlineSeconds":       {1, -1},
+	"activeDeadlineSeconds":         {1, -1},
+	"ttlSecondsAfterFinished":       {0, -1},
+	"startingDeadlineSeconds":       {0, -1},
+	"terminationGracePeriodSeconds": {0, -1},
+	"expirationSeconds":             {minTokenLifetime, -1},
+
+	// пробы
+	"initialDelaySeconds": {0, -1},
+	"periodSeconds":       {1, -1},
+	"timeoutSeconds":      {1, -1},
+	"successThreshold":    {1, -1},
+	"failureThreshold":    {1, -1},
+
+	// HorizontalPodAutoscaler
+	"averageUtilization": {1, -1},
+}
+
+// validateIntValue проверяет целое поле по его диапазону из intFieldRanges; диапазон
+// выбирается по последнему сегменту field. Возвращает значение, если в нём нет ошибок.
+func validateIntValue(n *yaml.Node, field string, errs *[]ValidationError) (int, bool) {
+	r, ok := intFieldRanges[field[strings.LastIndex(field, ".")+1:]]
+	if !ok {
+		r = intRange{-1 << 31, -1}
+	}
+	before := len(*errs)
+	validateIntRange(n, field, r.min, r.max, errs)
+	// предупреждение о ведущем нуле значение не портит
+	for _, e := range (*errs)[before:] {
+		if e.Severity == SeverityError {
+			return 0, false
+		}
+	}
+	v, _ := strconv.Atoi(n.Value)
+	return v, true
+}
+
+// validateIntFields проверяет перечисленные целые поля mapping'а m, если они заданы.
+func validateIntFields(m *yaml.Node, prefix string, errs *[]ValidationError, names ...string) {
+	for _, name := range names {
+		if _, v := getMap(m, name); v != nil {
+			validateIntValue(v, prefix+name, errs)
+		}
+	}
+}
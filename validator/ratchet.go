@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// файл состояния --ratchet по умолчанию; его коммитят в репозиторий, так что
+// базой служит состояние предыдущего коммита
+const defaultRatchetState = ".validator-ratchet.json"
+
+// ratchetState — число находок по идентификаторам правил
+type ratchetState struct {
+	Rules map[string]int `json:"rules"`
+}
+
+func readRatchetState(file string) (*ratchetState, error) {
+	b, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st ratchetState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func writeRatchetState(file string, st *ratchetState) error {
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(b, '\n'), 0o644)
+}
+
+// ratchet сравнивает число находок по правилам с сохранённым и печатает выросшие.
+// Если роста нет, состояние перезаписывается текущим: счётчики могут только убывать.
+// Возвращает true, если хоть одно правило стало нарушаться чаще.
+func ratchet(w io.Writer, file string, counts map[string]int) (bool, error) {
+	prev, err := readRatchetState(file)
+	if err != nil {
+		return false, err
+	}
+	increased := false
+	if prev != nil {
+		ids := make([]string, 0, len(counts))
+		for id := range counts {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if counts[id] > prev.Rules[id] {
+				fmt.Fprintf(w, "ratchet: %s increased from %d to %d\n", id, prev.Rules[id], counts[id])
+				increased = true
+			}
+		}
+	}
+	if increased {
+		return true, nil
+	}
+	return false, writeRatchetState(file, &ratchetState{Rules: counts})
+}
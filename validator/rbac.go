@@ -0,0 +1,203 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const rbacAPIVersion = "rbac.authorization.k8s.io/v1"
+
+var (
+	resourceVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection",
+		"use", "bind", "escalate", "impersonate", "approve", "sign", "*"}
+	nonResourceVerbs = []string{"get", "post", "put", "patch", "delete", "head", "options", "*"}
+	subjectKinds     = []string{"User", "Group", "ServiceAccount"}
+	// ресурс во множественном числе, возможно с подресурсом: pods, pods/log
+	resourceNameRegex = regexp.MustCompile(`^(\*|[a-z][a-z0-9.-]*)(/(\*|[a-z][a-z0-9-]*))?$`)
+)
+
+func validateRole(top *yaml.Node, errs *[]ValidationError) {
+	validateRules(top, false, errs)
+}
+
+func validateClusterRole(top *yaml.Node, errs *[]ValidationError) {
+	if _, agg := getMap(top, "aggregationRule"); agg != nil && expectType(agg, yaml.MappingNode, "aggregationRule", errs) {
+		_, sels := getMap(agg, "clusterRoleSelectors")
+		if sels == nil {
+			*errs = append(*errs, errAt(agg, "aggregationRule.clusterRoleSelectors is required"))
+		} else if expectType(sels, yaml.SequenceNode, "aggregationRule.clusterRoleSelectors", errs) {
+			for _, s := range sels.Content {
+				validateOptionalSelector(s, "aggregationRule.clusterRoleSelectors", errs)
+			}
+		}
+	}
+	validateRules(top, true, errs)
+}
+
+func validateRules(top *yaml.Node, cluster bool, errs *[]ValidationError) {
+	_, rules := getMap(top, "rules")
+	if rules == nil || !expectType(rules, yaml.SequenceNode, "rules", errs) {
+		return
+	}
+	for _, r := range rules.Content {
+		if r.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(r, "rules must be array"))
+			continue
+		}
+		validatePolicyRule(r, cluster, errs)
+	}
+}
+
+func validatePolicyRule(r *yaml.Node, cluster bool, errs *[]ValidationError) {
+	_, resources := getMap(r, "resources")
+	k, urls := getMap(r, "nonResourceURLs")
+	if urls != nil {
+		if !cluster {
+			*errs = append(*errs, errAt(k, "rules.nonResourceURLs is allowed only in ClusterRole"))
+		} else if resources != nil {
+			*errs = append(*errs, errAt(k, "rules.nonResourceURLs is not allowed together with resources"))
+		}
+	}
+
+	allowed := resourceVerbs
+	if urls != nil && resources == nil {
+		allowed = nonResourceVerbs
+	}
+	_, verbs := getMap(r, "verbs")
+	if verbs == nil {
+		*errs = append(*errs, errAt(r, "rules.verbs is required"))
+	} else if expectNonEmpty(verbs, yaml.SequenceNode, "rules.verbs", errs) {
+		for _, v := range verbs.Content {
+			if !expectString(v, "rules.verbs", errs) {
+				continue
+			}
+			if !contains(allowed, v.Value) {
+				*errs = append(*errs, errAt(v, fmt.Sprintf("rules.verbs has unsupported value '%s'", v.Value)))
+			} else if v.Value == "*" && config.WarnRBACWildcards {
+				*errs = append(*errs, warnAt(v, "rules.verbs '*' grants every verb; list the verbs explicitly"))
+			}
+		}
+	}
+
+	if _, groups := getMap(r, "apiGroups"); groups != nil && expectType(groups, yaml.SequenceNode, "rules.apiGroups", errs) {
+		for _, g := range groups.Content {
+			if !expectType(g, yaml.ScalarNode, "rules.apiGroups", errs) || g.Value == "" || g.Value == "*" {
+				continue
+			}
+			if len(g.Value) > 253 || !dnsSubdomainRegex.MatchString(g.Value) {
+				*errs = append(*errs, errAt(g, fmt.Sprintf("rules.apiGroups has invalid format '%s'", g.Value)))
+			}
+		}
+	}
+	if resources != nil && expectType(resources, yaml.SequenceNode, "rules.resources", errs) {
+		for _, res := range resources.Content {
+			if !expectString(res, "rules.resources", errs) {
+				continue
+			}
+			if !resourceNameRegex.MatchString(res.Value) {
+				*errs = append(*errs, errAt(res, fmt.Sprintf("rules.resources has invalid format '%s'", res.Value)))
+			} else if strings.HasPrefix(res.Value, "*") && config.WarnRBACWildcards {
+				*errs = append(*errs, warnAt(res, "rules.resources '*' grants access to every resource; list the resources explicitly"))
+			}
+		}
+	}
+	if urls != nil && expectType(urls, yaml.SequenceNode, "rules.nonResourceURLs", errs) {
+		for _, u := range urls.Content {
+			if expectString(u, "rules.nonResourceURLs", errs) && !strings.HasPrefix(u.Value, "/") {
+				*errs = append(*errs, errAt(u, fmt.Sprintf("rules.nonResourceURLs has invalid format '%s'", u.Value)))
+			}
+		}
+	}
+}
+
+func validateRoleBinding(top *yaml.Node, errs *[]ValidationError) {
+	validateBinding(top, []string{"Role", "ClusterRole"}, errs)
+}
+
+func validateClusterRoleBinding(top *yaml.Node, errs *[]ValidationError) {
+	validateBinding(top, []string{"ClusterRole"}, errs)
+}
+
+func validateBinding(top *yaml.Node, roleKinds []string, errs *[]ValidationError) {
+	// roleRef (обязательное)
+	_, ref := getMap(top, "roleRef")
+	if ref == nil {
+		*errs = append(*errs, ValidationError{Msg: "roleRef is required"})
+	} else if expectType(ref, yaml.MappingNode, "roleRef", errs) {
+		_, group := getMap(ref, "apiGroup")
+		if group == nil {
+			*errs = append(*errs, errAt(ref, "roleRef.apiGroup is required"))
+		} else if expectString(group, "roleRef.apiGroup", errs) && group.Value != "rbac.authorization.k8s.io" {
+			*errs = append(*errs, errAt(group, fmt.Sprintf("roleRef.apiGroup has unsupported value '%s'", group.Value)))
+		}
+		_, kind := getMap(ref, "kind")
+		if kind == nil {
+			*errs = append(*errs, errAt(ref, "roleRef.kind is required"))
+		} else if expectString(kind, "roleRef.kind", errs) && !contains(roleKinds, kind.Value) {
+			*errs = append(*errs, errAt(kind, fmt.Sprintf("roleRef.kind has unsupported value '%s'", kind.Value)))
+		}
+		_, name := getMap(ref, "name")
+		if name == nil {
+			*errs = append(*errs, errAt(ref, "roleRef.name is required"))
+		} else {
+			expectString(name, "roleRef.name", errs)
+		}
+	}
+
+	// subjects (необязательное)
+	_, subjects := getMap(top, "subjects")
+	if subjects == nil || !expectType(subjects, yaml.SequenceNode, "subjects", errs) {
+		return
+	}
+	for _, s := range subjects.Content {
+		if s.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(s, "subjects must be array"))
+			continue
+		}
+		validateSubject(s, len(roleKinds) == 1, errs)
+	}
+}
+
+// validateSubject: для ClusterRoleBinding у ServiceAccount нет namespace по умолчанию.
+func validateSubject(s *yaml.Node, clusterBinding bool, errs *[]ValidationError) {
+	_, name := getMap(s, "name")
+	if name == nil {
+		*errs = append(*errs, errAt(s, "subjects.name is required"))
+	} else {
+		expectString(name, "subjects.name", errs)
+	}
+	_, kind := getMap(s, "kind")
+	if kind == nil {
+		*errs = append(*errs, errAt(s, "subjects.kind is required"))
+		return
+	}
+	if !expectString(kind, "subjects.kind", errs) {
+		return
+	}
+	if !contains(subjectKinds, kind.Value) {
+		*errs = append(*errs, errAt(kind, fmt.Sprintf("subjects.kind has unsupported value '%s'", kind.Value)))
+		return
+	}
+
+	// apiGroup: rbac.authorization.k8s.io для User/Group, пустой для ServiceAccount
+	want := "rbac.authorization.k8s.io"
+	if kind.Value == "ServiceAccount" {
+		want = ""
+	}
+	if _, group := getMap(s, "apiGroup"); group != nil && expectType(group, yaml.ScalarNode, "subjects.apiGroup", errs) && group.Value != want {
+		*errs = append(*errs, errAt(group, fmt.Sprintf("subjects.apiGroup has unsupported value '%s' for kind '%s'", group.Value, kind.Value)))
+	}
+	if kind.Value == "ServiceAccount" {
+		if name != nil && name.Kind == yaml.ScalarNode && (len(name.Value) > 253 || !dnsSubdomainRegex.MatchString(name.Value)) {
+			*errs = append(*errs, errAt(name, fmt.Sprintf("subjects.name has invalid format '%s'", name.Value)))
+		}
+		if _, ns := getMap(s, "namespace"); ns == nil && clusterBinding {
+			*errs = append(*errs, errAt(s, "subjects.namespace is required for kind 'ServiceAccount'"))
+		}
+	} else if _, ns := getMap(s, "namespace"); ns != nil {
+		*errs = append(*errs, errAt(ns, fmt.Sprintf("subjects.namespace is not allowed for kind '%s'", kind.Value)))
+	}
+}
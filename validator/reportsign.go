@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+)
+
+// reportKey — ключ --sign-report; nil — отчёты не подписываются
+var reportKey *ecdsa.PrivateKey
+
+// parseSigningKey читает закрытый ключ ECDSA (PEM: EC PRIVATE KEY или PKCS#8).
+func parseSigningKey(file string) (*ecdsa.PrivateKey, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("signing key has invalid format")
+	}
+	if block.Type == "EC PRIVATE KEY" {
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ec, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("signing key must be ECDSA")
+	}
+	return ec, nil
+}
+
+// signReport пишет рядом с отчётом file отсоединённую подпись file.sig в формате
+// "cosign sign-blob": base64 подписи ECDSA над SHA-256 содержимого. Проверка —
+// cosign verify-blob --key pub.pem --signature file.sig file.
+func signReport(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, reportKey, sum[:])
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file+".sig", []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), 0o644)
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Подпись отчёта проверяется открытым ключом и перестаёт сходиться после правки одного байта.
+func TestSignReport(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	pod := writeTestFile(t, dir, "pod.yaml", testPod("app", "Vyp"))
+	keys := map[string]string{
+		"EC PRIVATE KEY": writeTestFile(t, dir, "sec1.pem", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}))),
+		"PRIVATE KEY":    writeTestFile(t, dir, "pkcs8.pem", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))),
+	}
+	verify := func(data, sig []byte) bool {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			t.Fatalf("signature is not base64: %q", sig)
+		}
+		sum := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(&key.PublicKey, sum[:], raw)
+	}
+
+	for keyType, keyFile := range keys {
+		for _, format := range []string{"json", "sarif"} {
+			t.Run(keyType+" "+format, func(t *testing.T) {
+				report := filepath.Join(t.TempDir(), "report."+format)
+				code, out := runCLI(t, "--output", format+"="+report, "--sign-report", keyFile, pod)
+				if code != 1 {
+					t.Fatalf("exit code %d, want 1; output:\n%s", code, out)
+				}
+				data, err := os.ReadFile(report)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sig, err := os.ReadFile(report + ".sig")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !verify(data, sig) {
+					t.Fatal("signature does not verify against the report")
+				}
+				data[len(data)/2] ^= 1
+				if verify(data, sig) {
+					t.Error("signature still verifies after a one-byte change")
+				}
+			})
+		}
+	}
+}
+
+func TestSignReportErrors(t *testing.T) {
+	dir := t.TempDir()
+	pod := writeTestFile(t, dir, "pod.yaml", testPod("app", "linux"))
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, err := x509.MarshalPKCS8PrivateKey(edPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey := writeTestFile(t, dir, "ed.pem", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ed})))
+	garbage := writeTestFile(t, dir, "garbage.pem", "not a key\n")
+	report := filepath.Join(dir, "report.json")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"report to stdout", []string{"--output", "json", "--sign-report", edKey}, "sign-report needs an --output written to a file"},
+		{"not ECDSA", []string{"--output", "json=" + report, "--sign-report", edKey}, "ed.pem: signing key must be ECDSA"},
+		{"not PEM", []string{"--output", "json=" + report, "--sign-report", garbage}, "garbage.pem: signing key has invalid format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out := runCLI(t, append(tt.args, pod)...)
+			if code != 2 || !strings.Contains(out, tt.want) {
+				t.Errorf("exit code %d, want 2 with %q; output:\n%s", code, tt.want, out)
+			}
+			if _, err := os.Stat(report + ".sig"); err == nil {
+				t.Error("signature written despite the error")
+			}
+		})
+	}
+}
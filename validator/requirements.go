@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Условные требования к набору полей объекта. Новое правило описывается данными:
+//
+//	exactlyOneOf("httpGet", "exec", "tcpSocket", "grpc")
+//	requires("endPort", "port")
+type requirementKind int
+
+const (
+	reqExactlyOne requirementKind = iota
+	reqAtMostOne
+	reqAtLeastOne
+	reqRequires
+)
+
+type requirement struct {
+	kind   requirementKind
+	fields []string
+}
+
+// exactlyOneOf: должно быть ровно одно из полей; при отсутствии всех требуется первое.
+func exactlyOneOf(fields ...string) requirement {
+	return requirement{kind: reqExactlyOne, fields: fields}
+}
+
+// atMostOneOf: поля взаимоисключающие, но все могут отсутствовать.
+func atMostOneOf(fields ...string) requirement {
+	return requirement{kind: reqAtMostOne, fields: fields}
+}
+
+func atLeastOneOf(fields ...string) requirement {
+	return requirement{kind: reqAtLeastOne, fields: fields}
+}
+
+// requires: если задано поле a, должно быть задано и b.
+func requires(a, b string) requirement {
+	return requirement{kind: reqRequires, fields: []string{a, b}}
+}
+
+// checkRequirements проверяет требования к mapping n; false, если хоть одно нарушено.
+func checkRequirements(n *yaml.Node, field string, reqs []requirement, errs *[]ValidationError) bool {
+	ok := true
+	for _, r := range reqs {
+		var present []string
+		var keys []*yaml.Node
+		for _, f := range r.fields {
+			if k, _ := getMap(n, f); k != nil {
+				present = append(present, f)
+				keys = append(keys, k)
+			}
+		}
+		switch r.kind {
+		case reqExactlyOne, reqAtMostOne:
+			if len(present) == 0 && r.kind == reqExactlyOne {
+				*errs = append(*errs, errAt(n, field+"."+r.fields[0]+" is required"))
+				ok = false
+			}
+			for i := 1; i < len(present); i++ {
+				*errs = append(*errs, errAt(keys[i], field+"."+present[i]+" is not allowed together with "+present[0]))
+				ok = false
+			}
+		case reqAtLeastOne:
+			if len(present) == 0 {
+				*errs = append(*errs, errAt(n, field+" must have at least one of "+strings.Join(r.fields, ", ")))
+				ok = false
+			}
+		case reqRequires:
+			if len(present) == 1 && present[0] == r.fields[0] {
+				*errs = append(*errs, errAt(keys[0], field+"."+r.fields[0]+" requires "+r.fields[1]))
+				ok = false
+			}
+		}
+	}
+	return ok
+}
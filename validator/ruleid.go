@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// категории встроенных проверок по шаблону сообщения
+var messageCategories = []struct{ marker, category string }{
+	{" is required", "required"},
+	{" must have ", "required"},
+	{" must not be empty", "empty"},
+	{" must be ", "type"},
+	{" has invalid format", "format"},
+	{" has unsupported value", "enum"},
+	{" value out of range", "range"},
+	{" is not allowed", "forbidden"},
+	{" conflicts with ", "conflict"},
+	{" is duplicated", "duplicate"},
+	{" does not match ", "reference"},
+	{" in YAML 1.1", "implicit-type"},
+	{" is not a canonical bool", "implicit-type"},
+	{" under restricted profile", "restricted"},
+	{" instead of a literal value", "secret"},
+}
+
+// RuleID возвращает идентификатор правила находки. Встроенным проверкам он выводится
+// из поля и шаблона сообщения: "containers.image has invalid format 'x'" → containers.image.format.
+func RuleID(e ValidationError) string {
+	if e.Rule != "" {
+		return e.Rule
+	}
+	field, _, _ := strings.Cut(e.Msg, " ")
+	for _, c := range messageCategories {
+		if strings.Contains(e.Msg, c.marker) {
+			return field + "." + c.category
+		}
+	}
+	return field
+}
+
+// docURL — ссылка на runbook правила id; пустая без docsBaseURL.
+func docURL(id string) string {
+	if config.DocsBaseURL == "" {
+		return ""
+	}
+	slug, ok := config.DocSlugs[id]
+	if !ok {
+		slug = id
+		for i := range rules {
+			if rules[i].ID == id && rules[i].Doc != "" {
+				slug = rules[i].Doc
+			}
+		}
+	}
+	return strings.TrimSuffix(config.DocsBaseURL, "/") + "/" + strings.TrimPrefix(slug, "/")
+}
+
+type finding struct {
+	file string
+	err  ValidationError
+}
+
+// printGroupedByRule выводит находки сгруппированными по правилу: сначала самые частые.
+func printGroupedByRule(w io.Writer, manifests []*manifest) {
+	groups := map[string][]finding{}
+	for _, m := range manifests {
+		for _, e := range m.errs {
+			id := RuleID(e)
+			groups[id] = append(groups[id], finding{file: findingFile(m.file, e), err: e})
+		}
+	}
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(groups[ids[i]]) != len(groups[ids[j]]) {
+			return len(groups[ids[i]]) > len(groups[ids[j]])
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		fmt.Fprintf(w, "%s (%d)\n", id, len(groups[id]))
+		for _, f := range groups[id] {
+			if f.err.Line == 0 {
+				fmt.Fprintf(w, "  %s\n", f.file)
+			} else {
+				fmt.Fprintf(w, "  %s:%d\n", f.file, f.err.Line)
+			}
+		}
+	}
+}
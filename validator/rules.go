@@ -0,0 +1,297 @@
+package validator
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Области, от которых отсчитывается путь правила
+const (
+	scopeObject    = "object"
+	scopePodSpec   = "podSpec"
+	scopeContainer = "container"
+)
+
+//go:embed rules.yaml
+var builtinRules []byte
+
+// Rule — простое декларативное правило: обязательность, перечисление, формат или диапазон.
+type Rule struct {
+	ID       string `yaml:"id"`
+	Scope    string `yaml:"scope"`
+	Path     string `yaml:"path"`
+	Field    string `yaml:"field"`
+	Severity string `yaml:"severity"`
+	// только для scope: object; пустой список — все kind
+	Kinds      []string `yaml:"kinds"`
+	Required   bool     `yaml:"required"`
+	Enum       []string `yaml:"enum"`
+	IgnoreCase bool     `yaml:"ignoreCase"`
+	Pattern    string   `yaml:"pattern"`
+	Min        *int     `yaml:"min"`
+	Max        *int     `yaml:"max"`
+	Disabled   bool     `yaml:"disabled"`
+	// для каталога правил (rules export)
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Links       []string `yaml:"links"`
+	// slug страницы runbook'а под docsBaseURL; по умолчанию id
+	Doc string `yaml:"doc"`
+	// structure, security, naming, resources, best-practice; по умолчанию structure
+	Categories []string `yaml:"categories"`
+
+	pattern *regexp.Regexp
+}
+
+// ruleBundle — файл правил с именем и версией; replace отбрасывает ранее загруженные правила.
+type ruleBundle struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	Replace bool   `yaml:"replace"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+var (
+	builtinBundle = mustParseRules(builtinRules)
+	rules         = builtinBundle.Rules
+	// загруженные наборы в порядке применения
+	ruleBundles = []*ruleBundle{builtinBundle}
+)
+
+func resetRules() {
+	rules, ruleBundles = builtinBundle.Rules, []*ruleBundle{builtinBundle}
+}
+
+func mustParseRules(b []byte) *ruleBundle {
+	r, err := parseRules(b)
+	if err != nil {
+		panic("builtin rules: " + err.Error())
+	}
+	return r
+}
+
+func parseRules(b []byte) (*ruleBundle, error) {
+	var f ruleBundle
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	for i := range f.Rules {
+		r := &f.Rules[i]
+		if r.ID == "" {
+			return nil, fmt.Errorf("rule %d: id is required", i+1)
+		}
+		if r.Path == "" && !r.Disabled {
+			return nil, fmt.Errorf("rule '%s': path is required", r.ID)
+		}
+		if r.Scope == "" {
+			r.Scope = scopeObject
+		}
+		if r.Scope != scopeObject && r.Scope != scopePodSpec && r.Scope != scopeContainer {
+			return nil, fmt.Errorf("rule '%s': scope has unsupported value '%s'", r.ID, r.Scope)
+		}
+		if r.Severity != "" && r.Severity != "error" && r.Severity != "warning" {
+			return nil, fmt.Errorf("rule '%s': severity has unsupported value '%s'", r.ID, r.Severity)
+		}
+		for _, c := range r.Categories {
+			if !contains(ruleCategories, c) {
+				return nil, fmt.Errorf("rule '%s': categories has unsupported value '%s'", r.ID, c)
+			}
+		}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule '%s': %w", r.ID, err)
+			}
+			r.pattern = re
+		}
+		if r.Field == "" {
+			r.Field = strings.ReplaceAll(r.Path, "[]", "")
+			switch r.Scope {
+			case scopePodSpec:
+				r.Field = "spec." + r.Field
+			case scopeContainer:
+				r.Field = "containers." + r.Field
+			}
+		}
+	}
+	return &f, nil
+}
+
+// loadRules накладывает пользовательский файл правил (или oci://-набор) на уже загруженные.
+func loadRules(path string) error {
+	b, err := readRulesSource(path)
+	if err != nil {
+		return err
+	}
+	bundle, err := parseRules(b)
+	if err != nil {
+		return err
+	}
+	if bundle.Name == "" {
+		bundle.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if bundle.Replace {
+		rules, ruleBundles = bundle.Rules, nil
+	} else {
+		rules = mergeRules(rules, bundle.Rules)
+	}
+	ruleBundles = append(ruleBundles, bundle)
+	return nil
+}
+
+// loadRulesDir загружает все *.yaml/*.yml каталога в порядке имён файлов.
+func loadRulesDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		if err := loadRules(filepath.Join(dir, e.Name())); err != nil {
+			return fmt.Errorf("%s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// rulesVersion описывает применённые наборы правил: "builtin 1, org 2024.10".
+func rulesVersion() string {
+	var parts []string
+	for _, b := range ruleBundles {
+		v := b.Version
+		if v == "" {
+			v = "unversioned"
+		}
+		parts = append(parts, b.Name+" "+v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mergeRules: правило с тем же id заменяет базовое, disabled убирает его.
+func mergeRules(base, over []Rule) []Rule {
+	merged := append([]Rule(nil), base...)
+	for _, o := range over {
+		i := 0
+		for i < len(merged) && merged[i].ID != o.ID {
+			i++
+		}
+		switch {
+		case i < len(merged) && o.Disabled:
+			merged = append(merged[:i], merged[i+1:]...)
+		case i < len(merged):
+			merged[i] = o
+		case !o.Disabled:
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
+
+// applyRules проверяет узел n правилами области scope; kind учитывается только для object.
+func applyRules(n *yaml.Node, scope, kind string, errs *[]ValidationError) {
+	for i := range rules {
+		r := &rules[i]
+		if r.Scope != scope || (len(r.Kinds) > 0 && !contains(r.Kinds, kind)) {
+			continue
+		}
+		timeRule(r.ID, func() { r.check(n, strings.Split(r.Path, "."), errs) })
+	}
+}
+
+func (r *Rule) check(n *yaml.Node, path []string, errs *[]ValidationError) {
+	key := path[0]
+	list := strings.HasSuffix(key, "[]")
+	key = strings.TrimSuffix(key, "[]")
+	_, v := getMap(n, key)
+	if v == nil {
+		if r.Required && n != nil && n.Kind == yaml.MappingNode {
+			r.report(n, r.Field+" is required", errs)
+		}
+		return
+	}
+	if list {
+		if v.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range v.Content {
+			if len(path) == 1 {
+				r.checkValue(item, errs)
+			} else {
+				r.check(item, path[1:], errs)
+			}
+		}
+		return
+	}
+	if len(path) == 1 {
+		r.checkValue(v, errs)
+	} else {
+		r.check(v, path[1:], errs)
+	}
+}
+
+// checkValue проверяет скаляр. Перечисление отвергает любое значение вне списка, в том числе
+// пустое, null и не строку; для диапазона и формата несовпадение типа и пустые значения
+// остаются встроенным проверкам.
+func (r *Rule) checkValue(v *yaml.Node, errs *[]ValidationError) {
+	if v.Kind != yaml.ScalarNode {
+		return
+	}
+	if len(r.Enum) > 0 && !r.inEnum(formatValue(v)) {
+		r.report(v, fmt.Sprintf("%s has unsupported value '%s'", r.Field, formatValue(v)), errs)
+	}
+	if v.Value == "" {
+		return
+	}
+	if r.Min != nil || r.Max != nil {
+		min, max := -1<<31, -1
+		if r.Min != nil {
+			min = *r.Min
+		}
+		if r.Max != nil {
+			max = *r.Max
+		}
+		before := len(*errs)
+		validateIntRange(v, r.Field, min, max, errs)
+		for i := before; i < len(*errs); i++ {
+			(*errs)[i].Rule = r.ID
+			if r.Severity == "warning" {
+				(*errs)[i].Severity = SeverityWarning
+			}
+		}
+		return
+	}
+	if scalarType(v) != "string" {
+		return
+	}
+	value := formatValue(v)
+	if r.pattern != nil && !r.pattern.MatchString(value) {
+		r.report(v, fmt.Sprintf("%s has invalid format '%s'", r.Field, value), errs)
+	}
+}
+
+func (r *Rule) inEnum(v string) bool {
+	for _, e := range r.Enum {
+		if e == v || (r.IgnoreCase && strings.EqualFold(e, v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) report(n *yaml.Node, msg string, errs *[]ValidationError) {
+	e := errAt(n, msg)
+	if r.Severity == "warning" {
+		e.Severity = SeverityWarning
+	}
+	e.Rule = r.ID
+	*errs = append(*errs, e)
+}
@@ -0,0 +1,132 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Значение os вне списка отвергается при любом типе скаляра, как до переноса проверки в правила.
+func TestOSEnumRejectsNonStrings(t *testing.T) {
+	tests := []struct {
+		os   string
+		want string
+	}{
+		{"1", "pod.yaml:6 os has unsupported value '1'"},
+		{"true", "pod.yaml:6 os has unsupported value 'true'"},
+		{"null", "pod.yaml:6 os has unsupported value 'null'"},
+		{`""`, "pod.yaml:6 os has unsupported value ''"},
+		{"Linux", ""},
+		{"{name: 1}", "pod.yaml:6 os has unsupported value '1'\n" +
+			`pod.yaml:6 spec.os.name must be string, got int (hint: quote the value: "1")`},
+		{`{name: ""}`, "pod.yaml:6 os has unsupported value ''"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.os, func(t *testing.T) {
+			file := writeTestFile(t, t.TempDir(), "pod.yaml", testPod("app", tt.os))
+			code, out := runCLI(t, file)
+			if got := strings.TrimSpace(out); got != tt.want {
+				t.Errorf("%s: output %q, want %q", filepath.Base(file), got, tt.want)
+			}
+			if wantCode := map[bool]int{true: 0, false: 1}[tt.want == ""]; code != wantCode {
+				t.Errorf("code = %d, want %d", code, wantCode)
+			}
+		})
+	}
+}
+
+func TestRuleChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		value string
+		want  []string
+	}{
+		{"pattern match", "pattern: '^v[0-9]+$'", "v12", nil},
+		{"pattern mismatch", "pattern: '^v[0-9]+$'", "12a", []string{"x.value has invalid format '12a'"}},
+		{"pattern folded scalar", "pattern: '^v[0-9]+$'", "|\n    v1\n", nil},
+		{"pattern empty", "pattern: '^v[0-9]+$'", `""`, nil},
+		{"pattern not string", "pattern: '^v[0-9]+$'", "12", nil},
+		{"enum match", "enum: [a, b]", "b", nil},
+		{"enum case", "enum: [a, b]", "A", []string{"x.value has unsupported value 'A'"}},
+		{"enum ignoreCase", "enum: [a, b]\n    ignoreCase: true", "A", nil},
+		{"enum empty", "enum: [a, b]", `""`, []string{"x.value has unsupported value ''"}},
+		{"enum null", "enum: [a, b]", "null", []string{"x.value has unsupported value 'null'"}},
+		{"enum int", "enum: [a, b]", "1", []string{"x.value has unsupported value '1'"}},
+		{"enum allows empty", "enum: [a, '']", `""`, nil},
+		{"enum mapping", "enum: [a, b]", "{a: 1}", nil},
+		{"range", "min: 1\n    max: 5", "3", nil},
+		{"out of range", "min: 1\n    max: 5", "9", []string{"value value out of range"}},
+		{"range not int", "min: 1\n    max: 5", "x", []string{"value must be int"}},
+		{"range empty", "min: 1\n    max: 5", `""`, nil},
+		{"required present empty", "required: true", `""`, nil},
+		{"warning severity", "enum: [a]\n    severity: warning", "b", []string{"warning: x.value has unsupported value 'b'"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := parseRules([]byte("rules:\n  - id: r\n    path: x.value\n    " + tt.rule + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &b.Rules[0]
+			m := parseTestManifest(t, "x:\n  value: "+tt.value+"\n")
+			var errs []ValidationError
+			r.check(m.docs[0], strings.Split(r.Path, "."), &errs)
+			var got []string
+			for _, e := range errs {
+				if e.Rule != r.ID {
+					t.Errorf("finding %q has rule %q, want %q", e.Msg, e.Rule, r.ID)
+				}
+				got = append(got, formatFinding("", ValidationError{Msg: e.Msg, Severity: e.Severity}))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("findings %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleRequired(t *testing.T) {
+	b, err := parseRules([]byte("rules:\n  - id: r\n    path: x.value\n    required: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &b.Rules[0]
+	for src, want := range map[string]string{
+		"x:\n  other: 1\n": "x.value is required",
+		// нет и родителя — находка на объекте
+		"y: 1\n": "x.value is required",
+		// родитель не mapping: его тип проверяют встроенные проверки
+		"x: [1]\n": "",
+	} {
+		var errs []ValidationError
+		r.check(parseTestManifest(t, src).docs[0], strings.Split(r.Path, "."), &errs)
+		got := ""
+		if len(errs) > 0 {
+			got = errs[0].Msg
+		}
+		if len(errs) > 1 || got != want {
+			t.Errorf("%q: findings %v, want %q", src, errs, want)
+		}
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"- path: x", "rule 1: id is required"},
+		{"- id: r", "rule 'r': path is required"},
+		{"- id: r\n  path: x\n  pattern: '(['", "rule 'r': error parsing regexp: missing closing ]: `[`"},
+		{"- id: r\n  path: x\n  scope: pod", "rule 'r': scope has unsupported value 'pod'"},
+		{"- id: r\n  path: x\n  severity: fatal", "rule 'r': severity has unsupported value 'fatal'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if _, err := parseRules([]byte("rules:\n" + tt.rule + "\n")); err == nil || err.Error() != tt.want {
+				t.Errorf("parseRules error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
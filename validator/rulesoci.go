@@ -0,0 +1,182 @@
+package validator
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Набор правил в реестре: OCI-артефакт, слой которого — YAML правил,
+// а подпись ed25519 этого слоя лежит в аннотации манифеста.
+const (
+	ociRulesScheme           = "oci://"
+	rulesLayerMediaType      = "application/vnd.bigbrother.validator.rules.v1+yaml"
+	rulesSignatureAnnotation = "io.bigbrother.validator.signature"
+)
+
+// readRulesSource читает файл правил с диска или, для oci://, из реестра.
+func readRulesSource(path string) ([]byte, error) {
+	if !strings.HasPrefix(path, ociRulesScheme) {
+		return os.ReadFile(path)
+	}
+	return fetchRulesBundle(strings.TrimPrefix(path, ociRulesScheme))
+}
+
+// fetchRulesBundle скачивает набор правил и проверяет подпись. Если реестр недоступен,
+// используется последняя закэшированная версия для этой ссылки (тоже с проверкой подписи).
+func fetchRulesBundle(ref string) ([]byte, error) {
+	key, err := rulesPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	data, sig, err := pullRulesBundle(ref)
+	if err != nil {
+		var cerr error
+		if data, sig, cerr = readCachedRules(ref); cerr != nil {
+			return nil, err
+		}
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return nil, errors.New("rules bundle signature verification failed")
+	}
+	if err := verifyChecksum(ociRulesScheme+ref, data); err != nil {
+		return nil, err
+	}
+	writeCachedRules(ref, data, sig)
+	return data, nil
+}
+
+func rulesPublicKey() (ed25519.PublicKey, error) {
+	if config.RulesPublicKey == "" {
+		return nil, errors.New("rulesPublicKey is required to verify rules from a registry")
+	}
+	b, err := base64.StdEncoding.DecodeString(config.RulesPublicKey)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, errors.New("rulesPublicKey has invalid format")
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+func pullRulesBundle(ref string) (data, sig []byte, err error) {
+	host, repo, tag, err := parseImageRef(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := registryFetch(fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repo, tag))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	var manifest struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, nil, err
+	}
+	digest := ""
+	for _, l := range manifest.Layers {
+		if l.MediaType == rulesLayerMediaType {
+			digest = l.Digest
+			break
+		}
+	}
+	if digest == "" {
+		return nil, nil, fmt.Errorf("no %s layer in %s", rulesLayerMediaType, ref)
+	}
+	if sig, err = base64.StdEncoding.DecodeString(manifest.Annotations[rulesSignatureAnnotation]); err != nil || len(sig) == 0 {
+		return nil, nil, fmt.Errorf("%s is not signed", ref)
+	}
+
+	// слой уже в кэше — повторно не скачиваем
+	if data, err := os.ReadFile(rulesCachePath(digest)); err == nil && verifyDigest(data, digest) == nil {
+		return data, sig, nil
+	}
+	blob, err := registryFetch(fmt.Sprintf("https://%s/v2/%s/blobs/%s", host, repo, digest))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer blob.Body.Close()
+	if data, err = io.ReadAll(blob.Body); err != nil {
+		return nil, nil, err
+	}
+	if err := verifyDigest(data, digest); err != nil {
+		return nil, nil, err
+	}
+	return data, sig, nil
+}
+
+func verifyDigest(data []byte, digest string) error {
+	sum := sha256.Sum256(data)
+	if "sha256:"+hex.EncodeToString(sum[:]) != digest {
+		return fmt.Errorf("rules bundle does not match digest %s", digest)
+	}
+	return nil
+}
+
+// cacheDir — каталог кэша валидатора для скачанных артефактов; пустой, если кэша нет.
+func cacheDir(name string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bigbrother-validator", name)
+}
+
+// Кэш: слой по digest'у и файл ссылки с его подписью для работы без реестра.
+func rulesCacheDir() string {
+	return cacheDir("rules")
+}
+
+func rulesCachePath(name string) string {
+	dir := rulesCacheDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(name))
+}
+
+type cachedRulesRef struct {
+	Digest    string `json:"digest"`
+	Signature []byte `json:"signature"`
+}
+
+func readCachedRules(ref string) (data, sig []byte, err error) {
+	b, err := os.ReadFile(rulesCachePath(ref) + ".json")
+	if err != nil {
+		return nil, nil, err
+	}
+	var c cachedRulesRef
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, nil, err
+	}
+	if data, err = os.ReadFile(rulesCachePath(c.Digest)); err != nil {
+		return nil, nil, err
+	}
+	return data, c.Signature, nil
+}
+
+// writeCachedRules сохраняет проверенный набор; ошибки кэша не мешают проверке.
+func writeCachedRules(ref string, data, sig []byte) {
+	dir := rulesCacheDir()
+	if dir == "" || os.MkdirAll(dir, 0o755) != nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	b, _ := json.Marshal(cachedRulesRef{Digest: digest, Signature: sig})
+	if os.WriteFile(rulesCachePath(digest), data, 0o644) == nil {
+		os.WriteFile(rulesCachePath(ref)+".json", b, 0o644)
+	}
+}
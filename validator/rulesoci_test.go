@@ -0,0 +1,144 @@
+package validator
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+const teamRulesBundle = "name: team\nrules:\n  - id: team-label\n    scope: object\n    path: metadata.labels.team\n    required: true\n"
+
+// testRegistry — реестр с одним набором правил policies/pod-rules:v3. layer — слой,
+// который отдаёт реестр, digest — его адрес в манифесте; sig пустой — набор не подписан.
+type testRegistry struct {
+	layer  []byte
+	digest string
+	sig    []byte
+}
+
+func (reg *testRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v2/policies/pod-rules/manifests/v3":
+		m := map[string]any{
+			"layers": []map[string]string{
+				{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": "sha256:00"},
+				{"mediaType": rulesLayerMediaType, "digest": reg.digest},
+			},
+			"annotations": map[string]string{rulesSignatureAnnotation: base64.StdEncoding.EncodeToString(reg.sig)},
+		}
+		json.NewEncoder(w).Encode(m)
+	case "/v2/policies/pod-rules/blobs/" + reg.digest:
+		w.Write(reg.layer)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// startTestRegistry поднимает реестр по TLS, подменяет клиент реестра и кэш
+// и возвращает ссылку на набор без схемы oci://.
+func startTestRegistry(t *testing.T, reg *testRegistry) (ref string, stop func()) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	srv := httptest.NewTLSServer(reg)
+	client := registryClient
+	registryClient = srv.Client()
+	t.Cleanup(func() {
+		srv.Close()
+		registryClient = client
+	})
+	return srv.Listener.Addr().String() + "/policies/pod-rules:v3", srv.Close
+}
+
+func rulesKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+func TestFetchRulesBundle(t *testing.T) {
+	pub, priv := rulesKey(t)
+	otherPub, _ := rulesKey(t)
+	bundle := []byte(teamRulesBundle)
+	tampered := []byte(strings.Replace(teamRulesBundle, "required: true", "required: trUe", 1))
+
+	tests := []struct {
+		name    string
+		reg     testRegistry
+		key     ed25519.PublicKey
+		wantErr string
+	}{
+		{name: "signed bundle", reg: testRegistry{bundle, "sha256:" + sha256Hex(bundle), ed25519.Sign(priv, bundle)}, key: pub},
+		{name: "layer does not match digest", reg: testRegistry{tampered, "sha256:" + sha256Hex(bundle), ed25519.Sign(priv, bundle)}, key: pub, wantErr: "does not match digest"},
+		{name: "one byte changed after signing", reg: testRegistry{tampered, "sha256:" + sha256Hex(tampered), ed25519.Sign(priv, bundle)}, key: pub, wantErr: "signature verification failed"},
+		{name: "signed with another key", reg: testRegistry{bundle, "sha256:" + sha256Hex(bundle), ed25519.Sign(priv, bundle)}, key: otherPub, wantErr: "signature verification failed"},
+		{name: "unsigned", reg: testRegistry{bundle, "sha256:" + sha256Hex(bundle), nil}, key: pub, wantErr: "is not signed"},
+		{name: "no public key", reg: testRegistry{bundle, "sha256:" + sha256Hex(bundle), ed25519.Sign(priv, bundle)}, wantErr: "rulesPublicKey is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, _ := startTestRegistry(t, &tt.reg)
+			resetState()
+			t.Cleanup(resetState)
+			if tt.key != nil {
+				config.RulesPublicKey = base64.StdEncoding.EncodeToString(tt.key)
+			}
+			data, err := fetchRulesBundle(ref)
+			if tt.wantErr == "" {
+				if err != nil || string(data) != string(bundle) {
+					t.Errorf("fetchRulesBundle = %q, %v; want the bundle", data, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("fetchRulesBundle error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Без реестра берётся кэш, но его подпись проверяется: подменённый слой не принимается.
+func TestFetchRulesBundleCache(t *testing.T) {
+	pub, priv := rulesKey(t)
+	bundle := []byte(teamRulesBundle)
+	digest := "sha256:" + sha256Hex(bundle)
+	ref, stop := startTestRegistry(t, &testRegistry{bundle, digest, ed25519.Sign(priv, bundle)})
+	resetState()
+	t.Cleanup(resetState)
+	config.RulesPublicKey = base64.StdEncoding.EncodeToString(pub)
+	if _, err := fetchRulesBundle(ref); err != nil {
+		t.Fatal(err)
+	}
+	stop()
+	if data, err := fetchRulesBundle(ref); err != nil || string(data) != string(bundle) {
+		t.Fatalf("offline fetchRulesBundle = %q, %v; want the cached bundle", data, err)
+	}
+	if err := os.WriteFile(rulesCachePath(digest), []byte(strings.Replace(teamRulesBundle, "team", "Team", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fetchRulesBundle(ref); err == nil || !strings.Contains(err.Error(), "signature verification failed") {
+		t.Errorf("fetchRulesBundle error = %v, want a signature failure for the tampered cache", err)
+	}
+}
+
+// --rules oci://... загружает подписанный набор и применяет его правила.
+func TestRulesFromRegistry(t *testing.T) {
+	pub, priv := rulesKey(t)
+	bundle := []byte(teamRulesBundle)
+	ref, _ := startTestRegistry(t, &testRegistry{bundle, "sha256:" + sha256Hex(bundle), ed25519.Sign(priv, bundle)})
+	dir := t.TempDir()
+	configPath := writeTestFile(t, dir, "config.yml", "rulesPublicKey: "+base64.StdEncoding.EncodeToString(pub)+"\n")
+	pod := writeTestFile(t, dir, "pod.yaml", testPod("app", "linux"))
+	code, out := runCLI(t, "--config", configPath, "--rules", ociRulesScheme+ref, pod)
+	if code != 1 || !strings.Contains(out, "metadata.labels.team is required") {
+		t.Errorf("exit code %d, want 1 with the bundle's finding; output:\n%s", code, out)
+	}
+}
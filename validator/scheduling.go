@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// встроенные классы, которым разрешён префикс system-
+var systemPriorityClasses = []string{"system-cluster-critical", "system-node-critical"}
+
+func validatePriorityClassName(n *yaml.Node, errs *[]ValidationError) {
+	if !expectString(n, "spec.priorityClassName", errs) {
+		return
+	}
+	switch {
+	case len(n.Value) > 253 || !dnsSubdomainRegex.MatchString(n.Value):
+		*errs = append(*errs, errAt(n, fmt.Sprintf("spec.priorityClassName has invalid format '%s'", n.Value)))
+	case strings.HasPrefix(n.Value, "system-") && !contains(systemPriorityClasses, n.Value):
+		*errs = append(*errs, errAt(n, fmt.Sprintf("spec.priorityClassName has unsupported value '%s'", n.Value)))
+	case len(config.PriorityClasses) > 0 && !contains(systemPriorityClasses, n.Value) && !contains(config.PriorityClasses, n.Value):
+		*errs = append(*errs, errAt(n, fmt.Sprintf("spec.priorityClassName has unsupported value '%s'", n.Value)))
+	}
+}
+
+func validatePreemptionPolicy(n *yaml.Node, errs *[]ValidationError) {
+	if !expectString(n, "spec.preemptionPolicy", errs) {
+		return
+	}
+	if n.Value != "PreemptLowerPriority" && n.Value != "Never" {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("spec.preemptionPolicy has unsupported value '%s'", n.Value)))
+	}
+}
+
+func validateRuntimeClassName(n *yaml.Node, errs *[]ValidationError) {
+	if !expectString(n, "spec.runtimeClassName", errs) {
+		return
+	}
+	if len(n.Value) > 253 || !dnsSubdomainRegex.MatchString(n.Value) {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("spec.runtimeClassName has invalid format '%s'", n.Value)))
+	} else if len(config.RuntimeClasses) > 0 && !contains(config.RuntimeClasses, n.Value) {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("spec.runtimeClassName has unsupported value '%s'", n.Value)))
+	}
+}
+
+func validateSchedulerName(n *yaml.Node, errs *[]ValidationError) {
+	if !expectString(n, "spec.schedulerName", errs) {
+		return
+	}
+	if len(n.Value) > 253 || !dnsSubdomainRegex.MatchString(n.Value) {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("spec.schedulerName has invalid format '%s'", n.Value)))
+	}
+}
@@ -0,0 +1,225 @@
+package validator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaLocationData — поля шаблона --schema-location, как у kubeconform:
+// {{ .NormalizedKubernetesVersion }}/{{ .ResourceKind }}{{ .KindSuffix }}.json
+type schemaLocationData struct {
+	NormalizedKubernetesVersion string
+	StrictSuffix                string
+	ResourceKind                string
+	ResourceAPIVersion          string
+	Group                       string
+	KindSuffix                  string
+}
+
+var schemaClient = &http.Client{Timeout: 10 * time.Second}
+
+// errSchemaNotFound — ни по одному адресу схемы для kind нет
+var errSchemaNotFound = errors.New("schema not found")
+
+// schemas — схемы, уже загруженные за прогон, по "apiVersion/kind"
+var (
+	schemas   = map[string]*jsonSchema{}
+	schemasMu sync.Mutex
+)
+
+// validateSchema проверяет документ JSON-схемой его kind из schemaLocations.
+func validateSchema(top *yaml.Node, errs *[]ValidationError) {
+	_, kind := getMap(top, "kind")
+	_, api := getMap(top, "apiVersion")
+	if kind == nil || api == nil || kind.Kind != yaml.ScalarNode || api.Kind != yaml.ScalarNode {
+		return
+	}
+	s, err := schemaFor(kind.Value, api.Value)
+	switch {
+	case errors.Is(err, errSchemaNotFound):
+		*errs = append(*errs, warnAt(kind, fmt.Sprintf("kind '%s' does not match any schema location", kind.Value)))
+	case err != nil:
+		*errs = append(*errs, errAt(kind, fmt.Sprintf("kind '%s' schema could not be loaded: %v", kind.Value, err)))
+	default:
+		s.validate(top, "", errs)
+	}
+}
+
+func schemaFor(kind, apiVersion string) (*jsonSchema, error) {
+	key := apiVersion + "/" + kind
+	schemasMu.Lock()
+	s, ok := schemas[key]
+	schemasMu.Unlock()
+	if ok {
+		return s, nil
+	}
+	b, err := fetchSchema(kind, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	var root map[string]any
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, err
+	}
+	s = &jsonSchema{root: root}
+	schemasMu.Lock()
+	schemas[key] = s
+	schemasMu.Unlock()
+	return s, nil
+}
+
+// fetchSchema перебирает schemaLocations по порядку. Отсутствие схемы (404, нет файла)
+// ведёт к следующему адресу; при недоступности сервера берётся копия из кэша.
+// Скачанные схемы сверяются с закреплёнными суммами и подписью, локальные файлы — нет.
+func fetchSchema(kind, apiVersion string) ([]byte, error) {
+	data := schemaData(kind, apiVersion)
+	var firstErr error
+	for _, loc := range config.SchemaLocations {
+		tmpl, err := template.New("schema").Parse(loc)
+		if err != nil {
+			return nil, fmt.Errorf("schema location '%s': %w", loc, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("schema location '%s': %w", loc, err)
+		}
+		url := buf.String()
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			b, err := os.ReadFile(url)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return b, err
+		}
+		b, sig, err := downloadSchema(url)
+		if errors.Is(err, errSchemaNotFound) {
+			continue
+		}
+		cached := false
+		if err != nil {
+			if b, sig, cached = readCachedSchema(url); !cached {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+		}
+		if err := verifySchema(url, b, sig); err != nil {
+			return nil, err
+		}
+		if !cached {
+			writeCachedSchema(url, b, sig)
+		}
+		return b, nil
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, errSchemaNotFound
+}
+
+// downloadSchema скачивает схему и, если задан cosignPublicKey, её подпись <url>.sig.
+func downloadSchema(url string) (data, sig []byte, err error) {
+	if data, err = download(url); err != nil {
+		return nil, nil, err
+	}
+	if config.cosignKey != nil {
+		if sig, err = download(url + ".sig"); errors.Is(err, errSchemaNotFound) {
+			return nil, nil, fmt.Errorf("%s.sig: signature not found", url)
+		}
+	}
+	return data, sig, err
+}
+
+func download(url string) ([]byte, error) {
+	resp, err := schemaClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, errSchemaNotFound
+	}
+	return nil, fmt.Errorf("%s: %s", url, resp.Status)
+}
+
+// verifySchema проверяет закреплённую сумму и подпись cosign скачанной схемы.
+func verifySchema(url string, data, sig []byte) error {
+	if err := verifyChecksum(url, data); err != nil {
+		return err
+	}
+	if config.cosignKey != nil {
+		return verifyCosignBlob(url, data, sig)
+	}
+	return nil
+}
+
+// Кэш: схема и подпись по хэшу адреса; копия проверяется так же, как скачанная.
+func readCachedSchema(url string) (data, sig []byte, ok bool) {
+	data, err := os.ReadFile(schemaCachePath(url))
+	if err != nil {
+		return nil, nil, false
+	}
+	sig, _ = os.ReadFile(schemaCachePath(url) + ".sig")
+	return data, sig, true
+}
+
+func writeCachedSchema(url string, data, sig []byte) {
+	dir := cacheDir("schemas")
+	if dir == "" || os.MkdirAll(dir, 0o755) != nil {
+		return
+	}
+	if os.WriteFile(schemaCachePath(url), data, 0o644) == nil && sig != nil {
+		os.WriteFile(schemaCachePath(url)+".sig", sig, 0o644)
+	}
+}
+
+// schemaData заполняет шаблон для kind и apiVersion ("apps/v1" → group apps, version v1).
+func schemaData(kind, apiVersion string) schemaLocationData {
+	group, version, ok := strings.Cut(apiVersion, "/")
+	if !ok {
+		group, version = "", apiVersion
+	}
+	suffix := ""
+	if group != "" {
+		suffix = "-" + strings.ToLower(strings.Split(group, ".")[0])
+	}
+	suffix += "-" + strings.ToLower(version)
+	k8s := config.KubernetesVersion
+	if k8s == "" {
+		k8s = "master"
+	} else if k8s != "master" && !strings.HasPrefix(k8s, "v") {
+		k8s = "v" + k8s
+	}
+	return schemaLocationData{
+		NormalizedKubernetesVersion: k8s,
+		StrictSuffix:                "-strict",
+		ResourceKind:                strings.ToLower(kind),
+		ResourceAPIVersion:          version,
+		Group:                       group,
+		KindSuffix:                  suffix,
+	}
+}
+
+func schemaCachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir("schemas"), hex.EncodeToString(sum[:])+".json")
+}
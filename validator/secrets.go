@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// имена переменных, в которых обычно лежат секреты
+	secretNameRegex = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|PWD|SECRET|TOKEN|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?)`)
+	// значения, узнаваемые по формату
+	secretValueRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`),
+		regexp.MustCompile(`\bxox[abpr]-[A-Za-z0-9-]{10,}`),
+	}
+	// порог энтропии (бит на символ) для длинных случайных строк
+	secretEntropyMin = 4.0
+	secretEntropyLen = 20
+)
+
+// validateEnvSecrets ищет секреты, записанные в env[].value открытым текстом.
+func validateEnvSecrets(c *yaml.Node, errs *[]ValidationError) {
+	_, env := getMap(c, "env")
+	if env == nil || env.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, e := range env.Content {
+		_, name := getMap(e, "name")
+		_, val := getMap(e, "value")
+		if name == nil || val == nil || val.Kind != yaml.ScalarNode || val.Value == "" {
+			continue
+		}
+		if secretAllowed(name.Value, val.Value) {
+			continue
+		}
+		reason := ""
+		switch {
+		case matchesAny(val.Value, secretValueRegexes):
+			reason = "looks like a credential"
+		case secretNameRegex.MatchString(name.Value):
+			reason = "is named like a secret"
+		case len(val.Value) >= secretEntropyLen && !strings.ContainsAny(val.Value, " /") && shannonEntropy(val.Value) >= secretEntropyMin:
+			reason = "looks like a random token"
+		default:
+			continue
+		}
+		*errs = append(*errs, warnAt(val, fmt.Sprintf("containers.env '%s' %s; use valueFrom.secretKeyRef instead of a literal value", name.Value, reason)))
+	}
+}
+
+func secretAllowed(name, value string) bool {
+	for _, re := range config.secretAllowlist {
+		if re.MatchString(name) || re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(s string, res []*regexp.Regexp) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func shannonEntropy(s string) float64 {
+	freq := map[rune]float64{}
+	n := 0.0
+	for _, r := range s {
+		freq[r]++
+		n++
+	}
+	h := 0.0
+	for _, f := range freq {
+		p := f / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,198 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	sysctlNameRegex = regexp.MustCompile(`^([a-z0-9]([-_a-z0-9]*[a-z0-9])?[./])*[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`)
+	// sysctl, которые kubelet разрешает без --allowed-unsafe-sysctls
+	safeSysctls = []string{
+		"kernel.shm_rmid_forced",
+		"net.ipv4.ip_local_port_range",
+		"net.ipv4.ip_local_reserved_ports",
+		"net.ipv4.ip_unprivileged_port_start",
+		"net.ipv4.ping_group_range",
+		"net.ipv4.tcp_fin_timeout",
+		"net.ipv4.tcp_keepalive_intvl",
+		"net.ipv4.tcp_keepalive_probes",
+		"net.ipv4.tcp_keepalive_time",
+		"net.ipv4.tcp_syncookies",
+	}
+	profileTypes = []string{"RuntimeDefault", "Localhost", "Unconfined"}
+)
+
+// restrictedAt — находка политики restricted-профиля: предупреждение
+// или ошибка при enforceRestricted.
+func restrictedAt(n *yaml.Node, msg string) ValidationError {
+	if config.EnforceRestricted {
+		return errAt(n, msg)
+	}
+	return warnAt(n, msg)
+}
+
+func validatePodSecurityContext(sc *yaml.Node, errs *[]ValidationError) {
+	if !expectType(sc, yaml.MappingNode, "spec.securityContext", errs) {
+		return
+	}
+	if _, sysctls := getMap(sc, "sysctls"); sysctls != nil {
+		validateSysctls(sysctls, errs)
+	}
+	validateSecurityProfiles(sc, "spec.securityContext", errs)
+}
+
+func validateContainerSecurityContext(sc *yaml.Node, errs *[]ValidationError) {
+	if !expectType(sc, yaml.MappingNode, "containers.securityContext", errs) {
+		return
+	}
+	validateSecurityProfiles(sc, "containers.securityContext", errs)
+}
+
+func validateSysctls(n *yaml.Node, errs *[]ValidationError) {
+	const field = "spec.securityContext.sysctls"
+	if !expectType(n, yaml.SequenceNode, field, errs) {
+		return
+	}
+	seen := map[string]bool{}
+	for _, s := range n.Content {
+		if s.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(s, field+" must be array"))
+			continue
+		}
+		if _, val := getMap(s, "value"); val == nil {
+			*errs = append(*errs, errAt(s, field+".value is required"))
+		} else {
+			expectString(val, field+".value", errs)
+		}
+		_, name := getMap(s, "name")
+		if name == nil {
+			*errs = append(*errs, errAt(s, field+".name is required"))
+			continue
+		}
+		if !expectString(name, field+".name", errs) {
+			continue
+		}
+		switch {
+		case len(name.Value) > 253 || !sysctlNameRegex.MatchString(name.Value):
+			*errs = append(*errs, errAt(name, fmt.Sprintf("%s.name has invalid format '%s'", field, name.Value)))
+		case seen[name.Value]:
+			*errs = append(*errs, errAt(name, fmt.Sprintf("%s.name '%s' is duplicated", field, name.Value)))
+		case activeProfile() == profileRestricted && !contains(safeSysctls, name.Value) && !contains(config.AllowedUnsafeSysctls, name.Value):
+			*errs = append(*errs, restrictedAt(name, fmt.Sprintf("%s.name '%s' is not a safe sysctl under restricted profile", field, name.Value)))
+		}
+		seen[name.Value] = true
+	}
+}
+
+// validateSecurityProfiles проверяет seccompProfile и appArmorProfile: localhostProfile
+// нужен ровно при type: Localhost.
+func validateSecurityProfiles(sc *yaml.Node, prefix string, errs *[]ValidationError) {
+	for _, p := range []string{"seccompProfile", "appArmorProfile"} {
+		_, prof := getMap(sc, p)
+		if prof == nil {
+			continue
+		}
+		field := prefix + "." + p
+		if !expectType(prof, yaml.MappingNode, field, errs) {
+			continue
+		}
+		_, typ := getMap(prof, "type")
+		if typ == nil {
+			*errs = append(*errs, errAt(prof, field+".type is required"))
+			continue
+		}
+		if !expectString(typ, field+".type", errs) {
+			continue
+		}
+		if !contains(profileTypes, typ.Value) {
+			*errs = append(*errs, errAt(typ, fmt.Sprintf("%s.type has unsupported value '%s'", field, typ.Value)))
+			continue
+		}
+		k, local := getMap(prof, "localhostProfile")
+		switch {
+		case typ.Value == "Localhost" && local == nil:
+			*errs = append(*errs, errAt(prof, field+".localhostProfile is required when type is 'Localhost'"))
+		case typ.Value == "Localhost":
+			expectString(local, field+".localhostProfile", errs)
+		case local != nil:
+			*errs = append(*errs, errAt(k, fmt.Sprintf("%s.localhostProfile is not allowed when type is '%s'", field, typ.Value)))
+		}
+		if typ.Value == "Unconfined" && activeProfile() == profileRestricted {
+			*errs = append(*errs, restrictedAt(typ, field+".type should not be 'Unconfined' under restricted profile"))
+		}
+	}
+}
+
+var capabilityRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// capabilityAllowlist — разрешённые capabilities.add: из конфига, а под restricted
+// по умолчанию только NET_BIND_SERVICE; nil — без ограничений.
+func capabilityAllowlist() []string {
+	if config.CapabilitiesAllowlist != nil {
+		return config.CapabilitiesAllowlist
+	}
+	if activeProfile() == profileRestricted {
+		return []string{"NET_BIND_SERVICE"}
+	}
+	return nil
+}
+
+// validateCapabilities проверяет securityContext.capabilities контейнера c;
+// под restricted требуется drop: [ALL].
+func validateCapabilities(c *yaml.Node, errs *[]ValidationError) {
+	const field = "containers.securityContext.capabilities"
+	_, sc := getMap(c, "securityContext")
+	_, caps := getMap(sc, "capabilities")
+	if caps != nil && !expectType(caps, yaml.MappingNode, field, errs) {
+		return
+	}
+	allow := capabilityAllowlist()
+	if _, add := getMap(caps, "add"); add != nil && expectType(add, yaml.SequenceNode, field+".add", errs) {
+		for _, a := range add.Content {
+			if !validateCapability(a, field+".add", errs) || allow == nil || contains(allow, a.Value) {
+				continue
+			}
+			msg := fmt.Sprintf("%s.add '%s' is not allowed", field, a.Value)
+			if config.CapabilitiesAllowlist == nil {
+				*errs = append(*errs, restrictedAt(a, msg+" under restricted profile"))
+			} else {
+				*errs = append(*errs, errAt(a, msg))
+			}
+		}
+	}
+	dropsAll := false
+	_, drop := getMap(caps, "drop")
+	if drop != nil && expectType(drop, yaml.SequenceNode, field+".drop", errs) {
+		for _, d := range drop.Content {
+			if validateCapability(d, field+".drop", errs) && d.Value == "ALL" {
+				dropsAll = true
+			}
+		}
+	}
+	if activeProfile() == profileRestricted && !dropsAll {
+		at := c
+		switch {
+		case drop != nil:
+			at = drop
+		case caps != nil:
+			at = caps
+		case sc != nil:
+			at = sc
+		}
+		*errs = append(*errs, restrictedAt(at, field+".drop must include 'ALL' under restricted profile"))
+	}
+}
+
+func validateCapability(n *yaml.Node, field string, errs *[]ValidationError) bool {
+	if !expectString(n, field, errs) {
+		return false
+	}
+	if !capabilityRegex.MatchString(n.Value) {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, n.Value)))
+		return false
+	}
+	return true
+}
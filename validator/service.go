@@ -0,0 +1,173 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var serviceTypes = []string{"ClusterIP", "NodePort", "LoadBalancer", "ExternalName"}
+
+func validateServiceSpec(spec *yaml.Node, errs *[]ValidationError) {
+	svcType := "ClusterIP"
+	if _, t := getMap(spec, "type"); t != nil && expectString(t, "spec.type", errs) {
+		if !contains(serviceTypes, t.Value) {
+			*errs = append(*errs, errAt(t, fmt.Sprintf("spec.type has unsupported value '%s'", t.Value)))
+		}
+		svcType = t.Value
+	}
+	if _, sel := getMap(spec, "selector"); sel != nil {
+		validateLabels(sel, "spec.selector", errs)
+	}
+
+	_, ports := getMap(spec, "ports")
+	if ports == nil {
+		if svcType != "ExternalName" {
+			*errs = append(*errs, errAt(spec, "spec.ports is required"))
+		}
+		return
+	}
+	if !expectType(ports, yaml.SequenceNode, "spec.ports", errs) {
+		return
+	}
+	names := map[string]bool{}
+	for _, p := range ports.Content {
+		if p.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(p, "spec.ports must be array"))
+			continue
+		}
+		// при нескольких портах имя обязательно и уникально
+		if _, name := getMap(p, "name"); name == nil {
+			if len(ports.Content) > 1 {
+				*errs = append(*errs, errAt(p, "spec.ports.name is required"))
+			}
+		} else if expectString(name, "spec.ports.name", errs) {
+			if !isPortName(name.Value) {
+				*errs = append(*errs, errAt(name, fmt.Sprintf("spec.ports.name has invalid format '%s'", name.Value)))
+			} else if names[name.Value] {
+				*errs = append(*errs, errAt(name, fmt.Sprintf("spec.ports.name '%s' is duplicated", name.Value)))
+			}
+			names[name.Value] = true
+		}
+		if _, port := getMap(p, "port"); port == nil {
+			*errs = append(*errs, errAt(p, "spec.ports.port is required"))
+		} else {
+			validateIntValue(port, "spec.ports.port", errs)
+		}
+		// targetPort — номер или имя порта контейнера
+		if _, target := getMap(p, "targetPort"); target != nil {
+			validatePortRef(target, "spec.ports.targetPort", errs)
+		}
+		validateIntFields(p, "spec.ports.", errs, "nodePort")
+		if _, proto := getMap(p, "protocol"); proto != nil && expectString(proto, "spec.ports.protocol", errs) {
+			if !contains(config.Protocols, strings.ToUpper(proto.Value)) {
+				*errs = append(*errs, errAt(proto, fmt.Sprintf("protocol has unsupported value '%s'", proto.Value)))
+			}
+		}
+	}
+}
+
+// podPorts — объект с подом, чьи метки выбирает селектор сервиса, и порты его контейнеров.
+// Только значения, без узлов YAML: хранится для всех файлов прогона (см. documentSet).
+type podPorts struct {
+	id     string
+	file   string
+	line   int
+	ns     string
+	labels map[string]string
+	// "8080/TCP" и имена портов
+	numbers map[string]bool
+	names   map[string]bool
+}
+
+func podPortsOf(top *yaml.Node, ref objectRef) (podPorts, bool) {
+	tpl := podTemplateOf(top, ref.kind)
+	_, meta := getMap(tpl, "metadata")
+	_, labels := getMap(meta, "labels")
+	spec := podSpecOf(top, ref.kind)
+	if labels == nil || labels.Kind != yaml.MappingNode || spec == nil {
+		return podPorts{}, false
+	}
+	p := podPorts{line: top.Line, ns: ref.namespace, labels: map[string]string{}, numbers: map[string]bool{}, names: map[string]bool{}}
+	for i := 0; i+1 < len(labels.Content); i += 2 {
+		p.labels[labels.Content[i].Value] = labels.Content[i+1].Value
+	}
+	_, conts := getMap(spec, "containers")
+	if conts == nil || conts.Kind != yaml.SequenceNode {
+		return p, true
+	}
+	for _, c := range conts.Content {
+		_, ports := getMap(c, "ports")
+		if ports == nil || ports.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, port := range ports.Content {
+			proto := "TCP"
+			if _, pr := getMap(port, "protocol"); pr != nil && pr.Kind == yaml.ScalarNode {
+				proto = strings.ToUpper(pr.Value)
+			}
+			if _, n := getMap(port, "containerPort"); n != nil && n.Kind == yaml.ScalarNode {
+				p.numbers[n.Value+"/"+proto] = true
+			}
+			if _, name := getMap(port, "name"); name != nil && name.Kind == yaml.ScalarNode {
+				p.names[name.Value] = true
+			}
+		}
+	}
+	return p, true
+}
+
+// validateServiceTarget сверяет targetPort сервиса с портами контейнеров подов, которые
+// выбирает его selector, среди всех проверяемых документов. Не найденный именованный порт —
+// ошибка: трафик не дойдёт до пода. Номер без объявленного containerPort работает, но
+// обычно означает опечатку, поэтому это предупреждение.
+func validateServiceTarget(top *yaml.Node, namespace string, pods []podPorts, errs *[]ValidationError) {
+	_, spec := getMap(top, "spec")
+	_, sel := getMap(spec, "selector")
+	_, ports := getMap(spec, "ports")
+	if sel == nil || sel.Kind != yaml.MappingNode || len(sel.Content) == 0 || ports == nil || ports.Kind != yaml.SequenceNode {
+		return
+	}
+	var selected []podPorts
+	for _, p := range pods {
+		if p.ns == namespace && labelsMatch(sel, p.labels) {
+			selected = append(selected, p)
+		}
+	}
+	for _, port := range ports.Content {
+		_, target := getMap(port, "targetPort")
+		if target == nil {
+			_, target = getMap(port, "port")
+		}
+		if target == nil || target.Kind != yaml.ScalarNode {
+			continue
+		}
+		proto := "TCP"
+		if _, pr := getMap(port, "protocol"); pr != nil && pr.Kind == yaml.ScalarNode {
+			proto = strings.ToUpper(pr.Value)
+		}
+		named := target.Tag == "!!str"
+		for _, p := range selected {
+			if named && !p.names[target.Value] {
+				e := errAt(target, fmt.Sprintf("spec.ports.targetPort '%s' does not match any container port name of %s", target.Value, p.id))
+				e.Hint = fmt.Sprintf("%s is defined at %s:%d", p.id, p.file, p.line)
+				*errs = append(*errs, e)
+			} else if !named && !p.numbers[target.Value+"/"+proto] {
+				e := warnAt(target, fmt.Sprintf("spec.ports.targetPort %s/%s does not match any containerPort of %s", target.Value, proto, p.id))
+				e.Hint = fmt.Sprintf("%s is defined at %s:%d", p.id, p.file, p.line)
+				*errs = append(*errs, e)
+			}
+		}
+	}
+}
+
+// labelsMatch — все пары selector есть среди меток пода.
+func labelsMatch(sel *yaml.Node, labels map[string]string) bool {
+	for i := 0; i+1 < len(sel.Content); i += 2 {
+		if v, ok := labels[sel.Content[i].Value]; !ok || v != sel.Content[i+1].Value {
+			return false
+		}
+	}
+	return true
+}
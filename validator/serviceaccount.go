@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+func validateServiceAccount(top *yaml.Node, errs *[]ValidationError) {
+	if _, am := getMap(top, "automountServiceAccountToken"); am != nil {
+		expectBool(am, "automountServiceAccountToken", errs)
+	}
+	for _, f := range []string{"imagePullSecrets", "secrets"} {
+		_, list := getMap(top, f)
+		if list == nil || !expectType(list, yaml.SequenceNode, f, errs) {
+			continue
+		}
+		for _, item := range list.Content {
+			if item.Kind != yaml.MappingNode {
+				*errs = append(*errs, errAt(item, f+" must be array"))
+				continue
+			}
+			_, name := getMap(item, "name")
+			if name == nil {
+				*errs = append(*errs, errAt(item, f+".name is required"))
+			} else {
+				validateDNSName(name, f+".name", errs)
+			}
+		}
+	}
+}
+
+func validateSecret(top *yaml.Node, errs *[]ValidationError) {
+	if _, t := getMap(top, "type"); t != nil {
+		expectType(t, yaml.ScalarNode, "type", errs)
+	}
+	if _, data := getMap(top, "data"); data != nil && expectType(data, yaml.MappingNode, "data", errs) {
+		for i := 0; i < len(data.Content)-1; i += 2 {
+			v := data.Content[i+1]
+			if !expectType(v, yaml.ScalarNode, "data['"+data.Content[i].Value+"']", errs) {
+				continue
+			}
+			if _, err := base64.StdEncoding.DecodeString(v.Value); err != nil {
+				*errs = append(*errs, errAt(v, fmt.Sprintf("data['%s'] has invalid format: not base64", data.Content[i].Value)))
+			}
+		}
+	}
+	if _, sd := getMap(top, "stringData"); sd != nil {
+		validateLabels(sd, "stringData", errs)
+	}
+}
+
+// validateServiceAccountRefs проверяет по набору документов, что serviceAccountName пода
+// и imagePullSecrets ServiceAccount ссылаются на существующие объекты.
+func validateServiceAccountRefs(top *yaml.Node, ref objectRef, objects map[objectRef]bool, errs *[]ValidationError) {
+	if ref.kind == "ServiceAccount" {
+		_, ips := getMap(top, "imagePullSecrets")
+		if ips == nil || ips.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range ips.Content {
+			_, name := getMap(item, "name")
+			if name == nil || name.Kind != yaml.ScalarNode {
+				continue
+			}
+			if !objects[objectRef{kind: "Secret", namespace: ref.namespace, name: name.Value}] {
+				*errs = append(*errs, warnAt(name, fmt.Sprintf("imagePullSecrets Secret '%s' is not found among validated documents", name.Value)))
+			}
+		}
+		return
+	}
+
+	spec := podSpecOf(top, ref.kind)
+	_, sa := getMap(spec, "serviceAccountName")
+	if sa == nil || sa.Kind != yaml.ScalarNode || sa.Value == "default" {
+		return
+	}
+	if !objects[objectRef{kind: "ServiceAccount", namespace: ref.namespace, name: sa.Value}] {
+		*errs = append(*errs, warnAt(sa, fmt.Sprintf("serviceAccountName '%s' is not found among validated documents", sa.Value)))
+	}
+}
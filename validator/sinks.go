@@ -0,0 +1,332 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Форматы --output
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputSARIF = "sarif"
+	// аттестация in-toto (см. intotoSink)
+	outputInToto = "intoto"
+)
+
+// sink — получатель находок: --output задаёт несколько одновременно,
+// например текст в stderr и SARIF в файл для CI.
+type sink interface {
+	// manifest получает находки проверенного файла
+	manifest(m *manifest) error
+	// fileError — файл не удалось прочитать или разобрать
+	fileError(file string, err error) error
+	// close дописывает вывод после последнего файла
+	close() error
+}
+
+// openSink разбирает --output format[=dest]; dest — stdout (по умолчанию), stderr или путь.
+// stdout — основной вывод w.
+func openSink(spec string, w io.Writer, groupBy string) (sink, error) {
+	format, dest, _ := strings.Cut(spec, "=")
+	if format != outputText && format != outputJSON && format != outputSARIF && format != outputInToto {
+		return nil, fmt.Errorf("unknown output format '%s'", format)
+	}
+	out := outputFile{w: w}
+	switch dest {
+	case "", "stdout", "-":
+	case "stderr":
+		out.w = os.Stderr
+	default:
+		f, err := os.Create(dest)
+		if err != nil {
+			return nil, err
+		}
+		out.w, out.f = f, f
+	}
+	switch format {
+	case outputJSON:
+		return &jsonSink{outputFile: out, results: []batchResult{}}, nil
+	case outputSARIF:
+		return &sarifSink{outputFile: out}, nil
+	case outputInToto:
+		return &intotoSink{outputFile: out}, nil
+	}
+	return &textSink{outputFile: out, groupBy: groupBy}, nil
+}
+
+// toFile — вывод --output идёт в файл, а не в stdout/stderr
+func toFile(spec string) bool {
+	_, dest, _ := strings.Cut(spec, "=")
+	return dest != "" && dest != "stdout" && dest != "stderr" && dest != "-"
+}
+
+// jsonToStdout — --output json пишет в основной вывод
+func jsonToStdout(spec string) bool {
+	format, dest, _ := strings.Cut(spec, "=")
+	return format == outputJSON && (dest == "" || dest == "stdout" || dest == "-")
+}
+
+// outputFile — куда пишет получатель; f задан, если вывод идёт в файл
+type outputFile struct {
+	w io.Writer
+	f *os.File
+}
+
+// closeFile закрывает файл отчёта и, при --sign-report, подписывает его.
+func (o outputFile) closeFile() error {
+	if o.f == nil {
+		return nil
+	}
+	if err := o.f.Close(); err != nil {
+		return err
+	}
+	if reportKey != nil {
+		return signReport(o.f.Name())
+	}
+	return nil
+}
+
+// encodeJSON пишет v с отступами и закрывает файл вывода.
+func (o outputFile) encodeJSON(v any) error {
+	enc := json.NewEncoder(o.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(v)
+	if cerr := o.closeFile(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// textSink — привычный вывод "file:line msg", при --group-by rule — по правилам в конце.
+type textSink struct {
+	outputFile
+	groupBy string
+	grouped []*manifest
+}
+
+func (s *textSink) manifest(m *manifest) error {
+	if s.groupBy == "rule" {
+		s.grouped = append(s.grouped, &manifest{file: m.file, errs: m.errs})
+		return nil
+	}
+	printErrors(s.w, m)
+	return nil
+}
+
+// ошибки чтения выводятся сразу, и при --group-by rule тоже: у них нет правила
+func (s *textSink) fileError(file string, err error) error {
+	printIOErr(s.w, file, err)
+	return nil
+}
+
+func (s *textSink) close() error {
+	if s.groupBy == "rule" {
+		printGroupedByRule(s.w, s.grouped)
+	}
+	return s.closeFile()
+}
+
+// jsonSink пишет массив {filename, error, findings} в формате ответов --stdin-batch=json.
+type jsonSink struct {
+	outputFile
+	results []batchResult
+}
+
+func (s *jsonSink) manifest(m *manifest) error {
+	res := batchResult{Filename: m.file, Findings: []jsonFinding{}}
+	for _, e := range m.errs {
+		res.Findings = append(res.Findings, toJSONFinding(e))
+	}
+	s.results = append(s.results, res)
+	return nil
+}
+
+func (s *jsonSink) fileError(file string, err error) error {
+	s.results = append(s.results, batchResult{Filename: file, Error: err.Error(), Findings: []jsonFinding{}})
+	return nil
+}
+
+// jsonReport — отчёт --output json при --timings: результаты и замеры правил за прогон
+type jsonReport struct {
+	Results []batchResult `json:"results"`
+	Timings []jsonTiming  `json:"timings"`
+}
+
+func (s *jsonSink) close() error {
+	// без --timings отчёт — массив результатов, как и раньше
+	if ruleTimings == nil {
+		return s.encodeJSON(s.results)
+	}
+	return s.encodeJSON(jsonReport{Results: s.results, Timings: ruleTimings.json()})
+}
+
+// sarifSink пишет SARIF 2.1.0 — формат, который принимают code scanning в GitHub и GitLab.
+type sarifSink struct {
+	outputFile
+	results []sarifResult
+	notes   []sarifNotification
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          *sarifProperties  `json:"properties,omitempty"`
+}
+
+// sarifProperties — JSON Pointer узла находки для инструментов автоисправления
+type sarifProperties struct {
+	Document int    `json:"document"`
+	Pointer  string `json:"jsonPointer"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+func sarifFileLocation(file string) sarifLocation {
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: filepath.ToSlash(file)}}}
+}
+
+func (s *sarifSink) manifest(m *manifest) error {
+	for _, e := range m.errs {
+		msg := e.Msg
+		if e.Hint != "" {
+			msg += " (hint: " + e.Hint + ")"
+		}
+		r := sarifResult{
+			RuleID:    RuleID(e),
+			Level:     "error",
+			Message:   sarifMessage{Text: msg},
+			Locations: []sarifLocation{sarifFileLocation(findingFile(m.file, e))},
+		}
+		if e.Severity == SeverityWarning {
+			r.Level = "warning"
+		}
+		if e.Line > 0 {
+			r.Locations[0].PhysicalLocation.Region = &sarifRegion{
+				StartLine: e.Line, StartColumn: e.Column, EndLine: e.EndLine, EndColumn: e.EndColumn,
+			}
+		}
+		if e.Path != nil {
+			r.Locations[0].LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: e.Path.String(), Kind: "member"}}
+			r.Properties = &sarifProperties{Document: e.Document, Pointer: e.Path.Pointer()}
+		}
+		if e.Fingerprint != "" {
+			r.PartialFingerprints = map[string]string{"validatorFingerprint/v1": e.Fingerprint}
+		}
+		s.results = append(s.results, r)
+	}
+	return nil
+}
+
+func (s *sarifSink) fileError(file string, err error) error {
+	s.notes = append(s.notes, sarifNotification{
+		Level:     "error",
+		Message:   sarifMessage{Text: err.Error()},
+		Locations: []sarifLocation{sarifFileLocation(file)},
+	})
+	return nil
+}
+
+func (s *sarifSink) close() error {
+	ids := map[string]bool{}
+	var driverRules []sarifRule
+	for _, r := range s.results {
+		if !ids[r.RuleID] {
+			ids[r.RuleID] = true
+			driverRules = append(driverRules, sarifRule{ID: r.RuleID, HelpURI: docURL(r.RuleID)})
+		}
+	}
+	sort.Slice(driverRules, func(i, j int) bool { return driverRules[i].ID < driverRules[j].ID })
+	if driverRules == nil {
+		driverRules = []sarifRule{}
+	}
+	results := s.results
+	if results == nil {
+		results = []sarifResult{}
+	}
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:        sarifTool{Driver: sarifDriver{Name: "validator", Version: rulesVersion(), Rules: driverRules}},
+			Invocations: []sarifInvocation{{ExecutionSuccessful: len(s.notes) == 0, ToolExecutionNotifications: s.notes}},
+			Results:     results,
+		}},
+	}
+	return s.encodeJSON(log)
+}
@@ -0,0 +1,258 @@
+package validator
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	tfManifestResourceRegex = regexp.MustCompile(`resource\s+"kubernetes_manifest"\s+"([^"]+)"\s*\{`)
+	tfYAMLDecodeFileRegex   = regexp.MustCompile(`^\s*manifest\s*=\s*yamldecode\(\s*file\(\s*"([^"]+)"\s*\)\s*\)`)
+	tfYAMLDecodeHeredoc     = regexp.MustCompile(`^\s*manifest\s*=\s*yamldecode\(\s*<<-?\s*([A-Za-z_][A-Za-z0-9_]*)\s*$`)
+	tfManifestAttrRegex     = regexp.MustCompile(`^\s*manifest\s*=`)
+)
+
+// tfPlan — нужная часть terraform show -json
+type tfPlan struct {
+	PlannedValues struct {
+		RootModule tfModule `json:"root_module"`
+	} `json:"planned_values"`
+}
+
+type tfModule struct {
+	Resources    []tfResource `json:"resources"`
+	ChildModules []tfModule   `json:"child_modules"`
+}
+
+type tfResource struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Values  struct {
+		Manifest any `json:"manifest"`
+	} `json:"values"`
+}
+
+// runTerraform — экспериментальная подкоманда terraform: проверяет манифесты ресурсов
+// kubernetes_manifest. Из каталога *.tf берутся manifest = yamldecode(file("...")) и
+// yamldecode(<<EOT ... EOT); манифест литералом HCL разобрать нельзя — для него нужен
+// план: terraform show -json plan.out > plan.json и validator terraform plan.json.
+func runTerraform(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("terraform", flag.ContinueOnError)
+	flags.SetOutput(w)
+	configPath := flags.String("config", "", "path to config file (default "+defaultConfigFile+" if present)")
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s terraform [flags] <dir|plan.json>\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	if err := loadConfig(*configPath); err != nil {
+		printIOErr(w, configFile(*configPath), err)
+		return 1
+	}
+	if config.RulesDir != "" {
+		if err := loadRulesDir(config.RulesDir); err != nil {
+			printIOErr(w, config.RulesDir, err)
+			return 1
+		}
+	}
+	if config.Rules != "" {
+		if err := loadRules(config.Rules); err != nil {
+			printIOErr(w, config.Rules, err)
+			return 1
+		}
+	}
+	src := flags.Arg(0)
+	var manifests []*manifest
+	var err error
+	if strings.HasSuffix(src, ".json") {
+		manifests, err = terraformPlanManifests(src)
+	} else {
+		manifests, err = terraformDirManifests(w, src)
+	}
+	if err != nil {
+		printIOErr(w, src, err)
+		return 1
+	}
+	for _, m := range manifests {
+		validateManifest(m)
+	}
+	validateDocumentSet(manifests)
+	index := resourceIndex{}
+	failed := false
+	for _, m := range manifests {
+		index.add(m)
+		finishManifest(w, m, false)
+		applyMessageTemplates(m)
+		for _, e := range m.errs {
+			if e.Severity != SeverityWarning {
+				failed = true
+			}
+			if m.src == nil {
+				// манифест из плана: строк у него нет, место — адрес ресурса и путь
+				at := m.file
+				if e.Path != nil {
+					at += " " + e.Path.String()
+				}
+				e.Line = 0
+				fmt.Fprintf(w, "%s: %s\n", at, formatFinding("", e))
+				continue
+			}
+			fmt.Fprintln(w, formatFinding(findingFile(filepath.Base(m.file), e), e))
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// terraformPlanManifests — манифесты kubernetes_manifest из плана в JSON; file — адрес ресурса.
+func terraformPlanManifests(file string) ([]*manifest, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var plan tfPlan
+	if err := json.Unmarshal(b, &plan); err != nil {
+		return nil, err
+	}
+	var manifests []*manifest
+	var walk func(mod tfModule) error
+	walk = func(mod tfModule) error {
+		for _, r := range mod.Resources {
+			if r.Type != "kubernetes_manifest" || r.Values.Manifest == nil {
+				continue
+			}
+			var doc yaml.Node
+			if err := doc.Encode(r.Values.Manifest); err != nil {
+				return fmt.Errorf("%s: %w", r.Address, err)
+			}
+			if doc.Kind != yaml.MappingNode {
+				return fmt.Errorf("%s: manifest must be object", r.Address)
+			}
+			manifests = append(manifests, &manifest{file: r.Address, docs: []*yaml.Node{&doc}})
+		}
+		for _, child := range mod.ChildModules {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return manifests, walk(plan.PlannedValues.RootModule)
+}
+
+// terraformDirManifests — манифесты ресурсов kubernetes_manifest из *.tf каталога.
+// Ресурсы, манифест которых задан не через yamldecode, перечисляются в w.
+func terraformDirManifests(w io.Writer, dir string) ([]*manifest, error) {
+	var tfFiles []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".terraform" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && filepath.Ext(p) == ".tf" {
+			tfFiles = append(tfFiles, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tfFiles)
+	var manifests []*manifest
+	for _, tf := range tfFiles {
+		b, err := os.ReadFile(tf)
+		if err != nil {
+			return nil, err
+		}
+		found, err := terraformFileManifests(w, tf, strings.Split(string(b), "\n"))
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, found...)
+	}
+	return manifests, nil
+}
+
+func terraformFileManifests(w io.Writer, tf string, lines []string) ([]*manifest, error) {
+	var manifests []*manifest
+	resource := ""
+	for i := 0; i < len(lines); i++ {
+		if m := tfManifestResourceRegex.FindStringSubmatch(lines[i]); m != nil {
+			resource = "kubernetes_manifest." + m[1]
+			continue
+		}
+		if resource == "" || !tfManifestAttrRegex.MatchString(lines[i]) {
+			continue
+		}
+		if m := tfYAMLDecodeFileRegex.FindStringSubmatch(lines[i]); m != nil {
+			p := strings.NewReplacer("${path.module}", filepath.Dir(tf), "${path.root}", filepath.Dir(tf)).Replace(m[1])
+			if !filepath.IsAbs(p) && !strings.HasPrefix(p, filepath.Dir(tf)) {
+				p = filepath.Join(filepath.Dir(tf), p)
+			}
+			mf, err := readManifest(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", resource, err)
+			}
+			manifests = append(manifests, mf)
+		} else if m := tfYAMLDecodeHeredoc.FindStringSubmatch(lines[i]); m != nil {
+			start := i + 1
+			end := start
+			for end < len(lines) && strings.TrimSpace(lines[end]) != m[1] {
+				end++
+			}
+			// отступ <<- у heredoc снимается по самой короткой строке, как в Terraform;
+			// пустые строки перед телом сохраняют номера строк .tf в находках
+			body := strings.Repeat("\n", start) + strings.Join(dedent(lines[start:end]), "\n") + "\n"
+			mf, err := parseManifest(tf, []byte(body))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", resource, err)
+			}
+			manifests = append(manifests, mf)
+			i = end
+		} else {
+			fmt.Fprintf(w, "%s:%d %s: manifest is not yamldecode(...); validate the plan JSON instead\n", filepath.Base(tf), i+1, resource)
+		}
+		resource = ""
+	}
+	return manifests, nil
+}
+
+func dedent(lines []string) []string {
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if n := len(l) - len(strings.TrimLeft(l, " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+		out[i] = l
+	}
+	return out
+}
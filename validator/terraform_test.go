@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTerraform(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "files/module/pod.yaml", testPod("file", "Vyp"))
+	writeTestFile(t, dir, "files/module/main.tf", `resource "kubernetes_manifest" "from_file" {
+  manifest = yamldecode(file("${path.module}/pod.yaml"))
+}
+
+resource "kubernetes_manifest" "literal" {
+  manifest = {
+    apiVersion = "v1"
+  }
+}
+`)
+	writeTestFile(t, dir, "heredoc/main.tf", "resource \"kubernetes_manifest\" \"inline\" {\n  manifest = yamldecode(<<-EOT\n"+
+		indentLines(testPod("inline", "Vyp"), "    ")+"  EOT\n  )\n}\n")
+	plan := writeTestFile(t, dir, "plan.json", `{"planned_values": {"root_module": {
+  "resources": [{"address": "kubernetes_manifest.good", "type": "kubernetes_manifest", "values": {"manifest": {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "good"}, "spec": {"containers": [{"name": "app", "image": "registry.bigbrother.io/app:v1.0.0", "resources": {"limits": {"cpu": 1, "memory": "1Gi"}, "requests": {"cpu": 1, "memory": "1Gi"}}}]}}}}],
+  "child_modules": [{"resources": [
+    {"address": "module.app.kubernetes_manifest.pod", "type": "kubernetes_manifest", "values": {"manifest": {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod"}, "spec": {"os": "Vyp", "containers": [{"name": "app", "image": "registry.bigbrother.io/app:v1.0.0", "resources": {"limits": {"cpu": 1, "memory": "1Gi"}, "requests": {"cpu": 1, "memory": "1Gi"}}}]}}}},
+    {"address": "module.app.null_resource.x", "type": "null_resource", "values": {}}
+  ]}]
+}}}`)
+
+	configPath := teamRulesConfig(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     []string
+	}{
+		{
+			name:     "yamldecode file",
+			args:     []string{filepath.Join(dir, "files")},
+			wantCode: 1,
+			want: []string{
+				"main.tf:6 kubernetes_manifest.literal: manifest is not yamldecode(...); validate the plan JSON instead",
+				"pod.yaml:6 os has unsupported value 'Vyp'",
+			},
+		},
+		{
+			name:     "heredoc keeps tf lines",
+			args:     []string{filepath.Join(dir, "heredoc")},
+			wantCode: 1,
+			want:     []string{"main.tf:8 os has unsupported value 'Vyp'"},
+		},
+		{
+			name:     "plan json",
+			args:     []string{plan},
+			wantCode: 1,
+			want:     []string{"module.app.kubernetes_manifest.pod spec.os: os has unsupported value 'Vyp'"},
+		},
+		{
+			name:     "rules from config",
+			args:     []string{"--config", configPath, plan},
+			wantCode: 1,
+			want: []string{
+				"kubernetes_manifest.good metadata: metadata.labels.team is required",
+				"module.app.kubernetes_manifest.pod spec.os: os has unsupported value 'Vyp'",
+				"module.app.kubernetes_manifest.pod metadata: metadata.labels.team is required",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out := runCLI(t, append([]string{"terraform"}, tt.args...)...)
+			if code != tt.wantCode {
+				t.Errorf("code = %d, want %d; output:\n%s", code, tt.wantCode, out)
+			}
+			if got := strings.Split(strings.TrimSpace(out), "\n"); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("output:\n%s\nwant:\n%s", out, strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func indentLines(s, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "")
+}
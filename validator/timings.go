@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// идентификатор в --timings для встроенных проверок, не вынесенных в правила
+const builtinTiming = "builtin"
+
+type ruleTiming struct {
+	calls int
+	total time.Duration
+}
+
+// timings — время выполнения по правилам за прогон (--timings)
+type timings struct {
+	byRule map[string]*ruleTiming
+	// время правил, замеренных внутри встроенных проверок; вычитается из builtin
+	nested time.Duration
+}
+
+// ruleTimings — замеры текущего прогона; nil, если --timings не задан
+var ruleTimings *timings
+
+// timeRule выполняет f и, если замеры включены, записывает время на правило id.
+func timeRule(id string, f func()) {
+	if ruleTimings == nil {
+		f()
+		return
+	}
+	start := time.Now()
+	f()
+	d := time.Since(start)
+	ruleTimings.add(id, d)
+	ruleTimings.nested += d
+}
+
+// timeBuiltin выполняет встроенные проверки f; время вложенных правил в builtin не входит.
+func timeBuiltin(f func()) {
+	if ruleTimings == nil {
+		f()
+		return
+	}
+	start, nested := time.Now(), ruleTimings.nested
+	f()
+	ruleTimings.add(builtinTiming, time.Since(start)-(ruleTimings.nested-nested))
+}
+
+func (t *timings) add(id string, d time.Duration) {
+	rt := t.byRule[id]
+	if rt == nil {
+		rt = &ruleTiming{}
+		t.byRule[id] = rt
+	}
+	rt.calls++
+	rt.total += d
+}
+
+// sorted возвращает идентификаторы правил от самых медленных.
+func (t *timings) sorted() []string {
+	ids := make([]string, 0, len(t.byRule))
+	for id := range t.byRule {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := t.byRule[ids[i]], t.byRule[ids[j]]
+		if a.total != b.total {
+			return a.total > b.total
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// print выводит правила от самых медленных.
+func (t *timings) print(w io.Writer) {
+	fmt.Fprintln(w, "timings:")
+	for _, id := range t.sorted() {
+		rt := t.byRule[id]
+		fmt.Fprintf(w, "  %s %v (%d calls)\n", id, rt.total, rt.calls)
+	}
+}
+
+// jsonTiming — замер правила в отчёте --output json
+type jsonTiming struct {
+	Rule    string  `json:"rule"`
+	Calls   int     `json:"calls"`
+	TotalMs float64 `json:"totalMs"`
+}
+
+// json возвращает замеры в том же порядке, что и print.
+func (t *timings) json() []jsonTiming {
+	out := []jsonTiming{}
+	for _, id := range t.sorted() {
+		rt := t.byRule[id]
+		out = append(out, jsonTiming{Rule: id, Calls: rt.calls, TotalMs: float64(rt.total) / float64(time.Millisecond)})
+	}
+	return out
+}
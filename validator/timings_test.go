@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// --timings с --output json кладёт замеры в отчёт, а не текстом рядом с ним.
+func TestTimingsJSON(t *testing.T) {
+	dir := t.TempDir()
+	pod := writeTestFile(t, dir, "pod.yaml", testPod("app", "Vyp"))
+	report := filepath.Join(dir, "report.json")
+	configPath := teamRulesConfig(t)
+
+	decode := func(t *testing.T, b []byte, rule string) {
+		t.Helper()
+		var r jsonReport
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Fatalf("report is not {results, timings}: %v\n%s", err, b)
+		}
+		rules := map[string]int{}
+		for _, tm := range r.Timings {
+			rules[tm.Rule] = tm.Calls
+			if tm.TotalMs < 0 {
+				t.Errorf("rule %s took %v ms", tm.Rule, tm.TotalMs)
+			}
+		}
+		if len(r.Results) != 1 || len(r.Results[0].Findings) == 0 {
+			t.Errorf("results = %+v, want the pod's findings", r.Results)
+		}
+		if rules[builtinTiming] == 0 || rules[rule] == 0 {
+			t.Errorf("timings = %+v, want builtin and %s", r.Timings, rule)
+		}
+	}
+
+	t.Run("stdout", func(t *testing.T) {
+		_, out := runCLI(t, "--timings", "--output", "json", pod)
+		if strings.Contains(out, "timings:") {
+			t.Errorf("text timings printed into the JSON stream:\n%s", out)
+		}
+		decode(t, []byte(out), "pod-os")
+	})
+	t.Run("file", func(t *testing.T) {
+		_, out := runCLI(t, "--config", configPath, "--timings", "--output", "json="+report, "--output", "text", pod)
+		if !strings.Contains(out, "timings:\n") || !strings.Contains(out, "  team-label ") {
+			t.Errorf("text output lacks timings:\n%s", out)
+		}
+		b, err := os.ReadFile(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decode(t, b, "team-label")
+	})
+	t.Run("without timings", func(t *testing.T) {
+		_, out := runCLI(t, "--output", "json", pod)
+		var results []batchResult
+		if err := json.Unmarshal([]byte(out), &results); err != nil {
+			t.Errorf("report without --timings is not an array: %v\n%s", err, out)
+		}
+	})
+}
@@ -0,0 +1,329 @@
+// Package validator проверяет Kubernetes-манифесты: структуру полей, форматы значений
+// и политики из конфигурации. Набор поддерживаемых kind расширяется через RegisterKind.
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+type ValidationError struct {
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+	Msg       string
+	Severity  Severity
+	// подсказка, как исправить
+	Hint string
+	// идентификатор правила; пустой для встроенных проверок (см. RuleID)
+	Rule string
+	// автоисправление (--fix), если оно возможно
+	Fix *Fix
+	// отпечаток находки, не зависящий от номеров строк (см. fingerprintFindings)
+	Fingerprint string
+	// исходный файл сгенерированного YAML (--origin-map, config.kubernetes.io/path);
+	// Line и EndLine тогда — строки в нём
+	File string
+	// путь к узлу находки в документе Document (номер в файле, от 0); пустой у находок без узла
+	Path     FieldPath
+	Document int
+
+	// узел, к которому относится ошибка; по нему вычисляется конец диапазона.
+	// У находок без строки (легаси-формат) узел только указывает место для отпечатка и --collapse
+	node *yaml.Node
+}
+
+func errAt(n *yaml.Node, msg string) ValidationError {
+	e := ValidationError{Line: nodeLine(n), Msg: msg, node: n}
+	if e.Line > 0 {
+		e.Column = n.Column
+	}
+	return e
+}
+
+func warnAt(n *yaml.Node, msg string) ValidationError {
+	e := errAt(n, msg)
+	e.Severity = SeverityWarning
+	return e
+}
+
+func getMap(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		k := m.Content[i]
+		v := m.Content[i+1]
+		if k.Value == key {
+			return k, v
+		}
+	}
+	return nil, nil
+}
+
+func expectType(node *yaml.Node, kind yaml.Kind, field string, errs *[]ValidationError) bool {
+	// "spec:" без значения — это null, а не объект другого типа
+	if node != nil && kind != yaml.ScalarNode && node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		*errs = append(*errs, errAt(node, field+" must not be empty"))
+		return false
+	}
+	if node == nil || node.Kind != kind {
+		t := map[yaml.Kind]string{
+			yaml.ScalarNode:   "string",
+			yaml.MappingNode:  "object",
+			yaml.SequenceNode: "list",
+		}[kind]
+		if t == "" {
+			t = "value"
+		}
+		*errs = append(*errs, errAt(node, fmt.Sprintf("%s must be %s", field, t)))
+		return false
+	}
+	// строковое поле, которому YAML вывел другой тип: name: true, tag: 1.10;
+	// пустое значение (null) остаётся на совести проверок "is required"
+	if kind == yaml.ScalarNode {
+		got := scalarType(node)
+		if got != "string" && got != "null" {
+			e := errAt(node, fmt.Sprintf("%s must be string, got %s", field, got))
+			e.Hint = quoteHint(node)
+			*errs = append(*errs, e)
+			return false
+		}
+		checkImplicitString(node, field, errs)
+	}
+	return true
+}
+
+// expectString — expectType для строковых полей, где пустое значение бессмысленно.
+func expectString(node *yaml.Node, field string, errs *[]ValidationError) bool {
+	if !expectType(node, yaml.ScalarNode, field, errs) {
+		return false
+	}
+	if strings.TrimSpace(node.Value) == "" {
+		*errs = append(*errs, errAt(node, field+" must not be empty"))
+		return false
+	}
+	return true
+}
+
+// expectNonEmpty — expectType для mapping/списков, которые не могут быть пустыми.
+func expectNonEmpty(node *yaml.Node, kind yaml.Kind, field string, errs *[]ValidationError) bool {
+	if !expectType(node, kind, field, errs) {
+		return false
+	}
+	if len(node.Content) == 0 {
+		*errs = append(*errs, errAt(node, field+" must not be empty"))
+		return false
+	}
+	return true
+}
+
+// expectBool проверяет булево поле; yes/no и прочие bool YAML 1.1 принимаются —
+// о них предупреждает validateBoolScalars.
+func expectBool(n *yaml.Node, field string, errs *[]ValidationError) bool {
+	if _, ok := yaml11Bool(n); ok {
+		return true
+	}
+	if n.Kind != yaml.ScalarNode || n.ShortTag() != "!!bool" {
+		*errs = append(*errs, errAt(n, field+" must be bool"))
+		return false
+	}
+	return true
+}
+
+// scalarType возвращает тип, который YAML вывел для скаляра по его тегу.
+func scalarType(n *yaml.Node) string {
+	switch n.ShortTag() {
+	case "!!bool":
+		return "bool"
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float"
+	case "!!null":
+		return "null"
+	case "!!timestamp":
+		return "timestamp"
+	case "!!binary":
+		return "binary"
+	}
+	return "string"
+}
+
+func nodeLine(n *yaml.Node) int {
+	if n != nil && n.Line > 0 {
+		return n.Line
+	}
+	return 0
+}
+
+// поддерживаемые kind; их apiVersion — в apiVersions
+type kindValidator struct {
+	validateSpec func(spec *yaml.Node, errs *[]ValidationError)
+	// для kind без spec (RBAC и т.п.) проверяются поля верхнего уровня
+	validateObject func(top *yaml.Node, errs *[]ValidationError)
+}
+
+var kinds = map[string]kindValidator{
+	"Pod":                     {validateSpec: validatePodSpec},
+	"Deployment":              {validateSpec: validateDeploymentSpec},
+	"StatefulSet":             {validateSpec: validateStatefulSetSpec},
+	"Job":                     {validateSpec: validateJobSpec},
+	"CronJob":                 {validateSpec: validateCronJobSpec},
+	"HorizontalPodAutoscaler": {validateSpec: validateHPASpec},
+	"Ingress":                 {validateSpec: validateIngressSpec},
+	"NetworkPolicy":           {validateSpec: validateNetworkPolicySpec},
+	"Role":                    {validateObject: validateRole},
+	"ClusterRole":             {validateObject: validateClusterRole},
+	"RoleBinding":             {validateObject: validateRoleBinding},
+	"ClusterRoleBinding":      {validateObject: validateClusterRoleBinding},
+	"Service":                 {validateSpec: validateServiceSpec},
+	"ServiceAccount":          {validateObject: validateServiceAccount},
+	"Secret":                  {validateObject: validateSecret},
+}
+
+func validateTop(top *yaml.Node, errs *[]ValidationError) {
+	_, kindNode := getMap(top, "kind")
+	kind := ""
+	if kindNode != nil && kindNode.Kind == yaml.ScalarNode {
+		kind = kindNode.Value
+	}
+	kv, known := kinds[kind]
+	if !known {
+		// для неизвестного kind spec проверяется как у Pod
+		kv = kinds["Pod"]
+	}
+
+	// apiVersion
+	_, apiNode := getMap(top, "apiVersion")
+	if apiNode == nil {
+		*errs = append(*errs, ValidationError{Msg: "apiVersion is required"})
+	} else if expectType(apiNode, yaml.ScalarNode, "apiVersion", errs) {
+		validateAPIVersion(apiNode, kind, errs)
+	}
+
+	// kind
+	if kindNode == nil {
+		*errs = append(*errs, ValidationError{Msg: "kind is required"})
+	} else if expectType(kindNode, yaml.ScalarNode, "kind", errs) && !known {
+		*errs = append(*errs, errAt(kindNode, fmt.Sprintf("kind has unsupported value '%s'", kindNode.Value)))
+	}
+
+	// metadata
+	_, meta := getMap(top, "metadata")
+	if meta == nil {
+		*errs = append(*errs, ValidationError{Msg: "metadata is required"})
+	} else if expectType(meta, yaml.MappingNode, "metadata", errs) {
+		validateObjectMeta(meta, errs)
+	}
+
+	if kv.validateObject != nil {
+		kv.validateObject(top, errs)
+	} else if _, spec := getMap(top, "spec"); spec == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec is required"})
+	} else if expectType(spec, yaml.MappingNode, "spec", errs) {
+		kv.validateSpec(spec, errs)
+	}
+
+	applyRules(top, scopeObject, kind, errs)
+	timeRule("forbiddenFields", func() { validateForbiddenFields(top, errs) })
+	timeRule("valuePolicies", func() { validateValuePolicies(top, errs) })
+	validateBoolScalars(top, "", errs)
+	if clusterContext != nil {
+		validateContextRefs(top, kind, errs)
+	}
+	if len(config.SchemaLocations) > 0 {
+		validateSchema(top, errs)
+	}
+}
+
+// validateForbiddenFields сообщает о каждом поле документа из forbiddenFields конфига.
+func validateForbiddenFields(top *yaml.Node, errs *[]ValidationError) {
+	for i, steps := range config.forbiddenFields {
+		for _, m := range matchPath(top, steps) {
+			*errs = append(*errs, errAt(m.key, config.ForbiddenFields[i]+" is not allowed"))
+		}
+	}
+}
+
+func validateObjectMeta(meta *yaml.Node, errs *[]ValidationError) {
+	_, name := getMap(meta, "name")
+	if name == nil {
+		*errs = append(*errs, ValidationError{Msg: "metadata.name is required"})
+	} else if expectType(name, yaml.ScalarNode, "metadata.name", errs) {
+		if strings.TrimSpace(name.Value) == "" {
+			*errs = append(*errs, errAt(name, "name is required"))
+		}
+	}
+
+	if _, ns := getMap(meta, "namespace"); ns != nil {
+		expectString(ns, "metadata.namespace", errs)
+	}
+
+	_, labels := getMap(meta, "labels")
+	if labels != nil {
+		validateLabels(labels, "metadata.labels", errs)
+	}
+	validateRequiredLabels(meta, labels, errs)
+}
+
+// validateRequiredLabels проверяет обязательные метки из конфига, по находке на метку.
+func validateRequiredLabels(meta, labels *yaml.Node, errs *[]ValidationError) {
+	for _, l := range config.RequiredLabels {
+		field := "metadata.labels." + l.Name
+		_, v := getMap(labels, l.Name)
+		switch {
+		case v == nil && labels != nil:
+			*errs = append(*errs, errAt(labels, field+" is required"))
+		case v == nil:
+			*errs = append(*errs, errAt(meta, field+" is required"))
+		case v.Kind != yaml.ScalarNode:
+		case strings.TrimSpace(v.Value) == "":
+			*errs = append(*errs, errAt(v, field+" must not be empty"))
+		case l.pattern != nil && !l.pattern.MatchString(formatValue(v)):
+			*errs = append(*errs, errAt(v, fmt.Sprintf("%s has invalid format '%s'", field, formatValue(v))))
+		}
+	}
+}
+
+func validateLabels(labels *yaml.Node, field string, errs *[]ValidationError) {
+	if expectType(labels, yaml.MappingNode, field, errs) {
+		for i := 0; i < len(labels.Content)-1; i += 2 {
+			v := labels.Content[i+1]
+			if v.Kind != yaml.ScalarNode {
+				*errs = append(*errs, errAt(v, field+" has invalid format ''"))
+				break
+			}
+		}
+	}
+}
+
+// validateValuePolicies проверяет значения по путям из valuePolicies конфига;
+// не скалярные значения пропускаются.
+func validateValuePolicies(top *yaml.Node, errs *[]ValidationError) {
+	for _, p := range config.ValuePolicies {
+		for _, m := range matchPath(top, p.steps) {
+			v := m.value
+			if v.Kind != yaml.ScalarNode {
+				continue
+			}
+			value := formatValue(v)
+			switch {
+			case p.Allow != nil && !contains(p.Allow, value):
+				*errs = append(*errs, errAt(v, fmt.Sprintf("%s has unsupported value '%s'", p.Path, value)))
+			case contains(p.Deny, value):
+				*errs = append(*errs, errAt(v, fmt.Sprintf("%s value '%s' is not allowed", p.Path, value)))
+			}
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package validator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// количество ресурса Kubernetes: 128Mi, 1G, 500m, 1e3
+	quantityRegex    = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)(([KMGTPE]i)|[numkMGTPE]|[eE][+-]?[0-9]+)?$`)
+	hugePagesMedium  = regexp.MustCompile(`^HugePages-[0-9]+(Ki|Mi|Gi)$`)
+	hostPathTypes    = []string{"", "DirectoryOrCreate", "Directory", "FileOrCreate", "File", "Socket", "CharDevice", "BlockDevice"}
+	emptyDirMediums  = []string{"", "Memory", "HugePages"}
+	volumeSources    = []string{"configMap", "secret", "emptyDir", "hostPath", "projected", "persistentVolumeClaim", "downwardAPI", "csi", "ephemeral", "nfs"}
+	volumeReqs       = []requirement{atMostOneOf(volumeSources...)}
+	projectionKinds  = []string{"configMap", "secret", "downwardAPI", "serviceAccountToken", "clusterTrustBundle"}
+	projectionReqs   = []requirement{exactlyOneOf(projectionKinds...)}
+	keyToPathReqs    = []requirement{requires("key", "path"), requires("path", "key")}
+	minTokenLifetime = 600
+)
+
+func validateVolumes(n *yaml.Node, errs *[]ValidationError) {
+	if !expectType(n, yaml.SequenceNode, "spec.volumes", errs) {
+		return
+	}
+	seen := map[string]bool{}
+	for _, v := range n.Content {
+		if v.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(v, "spec.volumes must be array"))
+			continue
+		}
+		_, name := getMap(v, "name")
+		if name == nil {
+			*errs = append(*errs, errAt(v, "spec.volumes.name is required"))
+		} else if expectString(name, "spec.volumes.name", errs) {
+			if len(name.Value) > 63 || !dnsLabelRegex.MatchString(name.Value) {
+				*errs = append(*errs, errAt(name, fmt.Sprintf("spec.volumes.name has invalid format '%s'", name.Value)))
+			} else if seen[name.Value] {
+				*errs = append(*errs, errAt(name, fmt.Sprintf("spec.volumes.name '%s' is duplicated", name.Value)))
+			}
+			seen[name.Value] = true
+		}
+		if !checkRequirements(v, "spec.volumes", volumeReqs, errs) {
+			continue
+		}
+		validateVolumeSource(v, errs)
+	}
+}
+
+func validateVolumeSource(v *yaml.Node, errs *[]ValidationError) {
+	if _, cm := getMap(v, "configMap"); cm != nil {
+		validateObjectProjection(cm, "spec.volumes.configMap", "name", errs)
+	}
+	if _, sec := getMap(v, "secret"); sec != nil {
+		validateObjectProjection(sec, "spec.volumes.secret", "secretName", errs)
+	}
+	if _, ed := getMap(v, "emptyDir"); ed != nil && expectType(ed, yaml.MappingNode, "spec.volumes.emptyDir", errs) {
+		if _, m := getMap(ed, "medium"); m != nil && expectType(m, yaml.ScalarNode, "spec.volumes.emptyDir.medium", errs) &&
+			!contains(emptyDirMediums, m.Value) && !hugePagesMedium.MatchString(m.Value) {
+			*errs = append(*errs, errAt(m, fmt.Sprintf("spec.volumes.emptyDir.medium has unsupported value '%s'", m.Value)))
+		}
+		if _, size := getMap(ed, "sizeLimit"); size != nil {
+			validateQuantity(size, "spec.volumes.emptyDir.sizeLimit", errs)
+		}
+	}
+	if _, hp := getMap(v, "hostPath"); hp != nil && expectType(hp, yaml.MappingNode, "spec.volumes.hostPath", errs) {
+		if _, path := getMap(hp, "path"); path == nil {
+			*errs = append(*errs, errAt(hp, "spec.volumes.hostPath.path is required"))
+		} else if expectString(path, "spec.volumes.hostPath.path", errs) && !strings.HasPrefix(path.Value, "/") {
+			*errs = append(*errs, errAt(path, fmt.Sprintf("spec.volumes.hostPath.path has invalid format '%s'", path.Value)))
+		}
+		if _, t := getMap(hp, "type"); t != nil && expectType(t, yaml.ScalarNode, "spec.volumes.hostPath.type", errs) && !contains(hostPathTypes, t.Value) {
+			*errs = append(*errs, errAt(t, fmt.Sprintf("spec.volumes.hostPath.type has unsupported value '%s'", t.Value)))
+		}
+	}
+	if _, pvc := getMap(v, "persistentVolumeClaim"); pvc != nil && expectType(pvc, yaml.MappingNode, "spec.volumes.persistentVolumeClaim", errs) {
+		if _, claim := getMap(pvc, "claimName"); claim == nil {
+			*errs = append(*errs, errAt(pvc, "spec.volumes.persistentVolumeClaim.claimName is required"))
+		} else {
+			validateDNSName(claim, "spec.volumes.persistentVolumeClaim.claimName", errs)
+		}
+		if _, ro := getMap(pvc, "readOnly"); ro != nil {
+			expectBool(ro, "spec.volumes.persistentVolumeClaim.readOnly", errs)
+		}
+	}
+	if _, pr := getMap(v, "projected"); pr != nil {
+		validateProjected(pr, errs)
+	}
+}
+
+// validateObjectProjection — configMap/secret: имя объекта, defaultMode и items.
+func validateObjectProjection(n *yaml.Node, field, nameKey string, errs *[]ValidationError) {
+	if !expectType(n, yaml.MappingNode, field, errs) {
+		return
+	}
+	if _, name := getMap(n, nameKey); name == nil {
+		*errs = append(*errs, errAt(n, field+"."+nameKey+" is required"))
+	} else {
+		validateDNSName(name, field+"."+nameKey, errs)
+	}
+	if _, mode := getMap(n, "defaultMode"); mode != nil {
+		validateFileMode(mode, field+".defaultMode", errs)
+	}
+	if _, opt := getMap(n, "optional"); opt != nil {
+		expectBool(opt, field+".optional", errs)
+	}
+	_, items := getMap(n, "items")
+	if items == nil || !expectType(items, yaml.SequenceNode, field+".items", errs) {
+		return
+	}
+	for _, it := range items.Content {
+		if it.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(it, field+".items must be array"))
+			continue
+		}
+		if !checkRequirements(it, field+".items", keyToPathReqs, errs) {
+			continue
+		}
+		if _, key := getMap(it, "key"); key == nil {
+			*errs = append(*errs, errAt(it, field+".items.key is required"))
+		} else {
+			expectString(key, field+".items.key", errs)
+		}
+		if _, path := getMap(it, "path"); path != nil {
+			validateRelativePath(path, field+".items.path", errs)
+		}
+		if _, mode := getMap(it, "mode"); mode != nil {
+			validateFileMode(mode, field+".items.mode", errs)
+		}
+	}
+}
+
+func validateProjected(n *yaml.Node, errs *[]ValidationError) {
+	const field = "spec.volumes.projected"
+	if !expectType(n, yaml.MappingNode, field, errs) {
+		return
+	}
+	if _, mode := getMap(n, "defaultMode"); mode != nil {
+		validateFileMode(mode, field+".defaultMode", errs)
+	}
+	_, sources := getMap(n, "sources")
+	if sources == nil || !expectType(sources, yaml.SequenceNode, field+".sources", errs) {
+		return
+	}
+	for _, s := range sources.Content {
+		if s.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(s, field+".sources must be array"))
+			continue
+		}
+		if !checkRequirements(s, field+".sources", projectionReqs, errs) {
+			continue
+		}
+		if _, cm := getMap(s, "configMap"); cm != nil {
+			validateObjectProjection(cm, field+".sources.configMap", "name", errs)
+		}
+		if _, sec := getMap(s, "secret"); sec != nil {
+			validateObjectProjection(sec, field+".sources.secret", "name", errs)
+		}
+		_, tok := getMap(s, "serviceAccountToken")
+		if tok == nil || !expectType(tok, yaml.MappingNode, field+".sources.serviceAccountToken", errs) {
+			continue
+		}
+		if _, path := getMap(tok, "path"); path == nil {
+			*errs = append(*errs, errAt(tok, field+".sources.serviceAccountToken.path is required"))
+		} else {
+			validateRelativePath(path, field+".sources.serviceAccountToken.path", errs)
+		}
+		validateIntFields(tok, field+".sources.serviceAccountToken.", errs, "expirationSeconds")
+	}
+}
+
+// validateRelativePath: путь внутри тома — относительный и без "..".
+func validateRelativePath(n *yaml.Node, field string, errs *[]ValidationError) {
+	if !expectString(n, field, errs) {
+		return
+	}
+	if strings.HasPrefix(n.Value, "/") || contains(strings.Split(n.Value, "/"), "..") {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, n.Value)))
+	}
+}
+
+// validateFileMode: права файла 0..0777; ведущий ноль здесь ожидаем и читается как восьмеричный.
+func validateFileMode(n *yaml.Node, field string, errs *[]ValidationError) {
+	short := field[strings.LastIndex(field, ".")+1:]
+	if n.Kind != yaml.ScalarNode || scalarType(n) != "int" {
+		*errs = append(*errs, errAt(n, short+" must be int"))
+		return
+	}
+	val, err := strconv.ParseInt(strings.Replace(n.Value, "0o", "0", 1), 0, 64)
+	if err != nil {
+		*errs = append(*errs, errAt(n, short+" must be int"))
+	} else if val < 0 || val > 0o777 {
+		*errs = append(*errs, errAt(n, short+" value out of range"))
+	}
+}
+
+func validateQuantity(n *yaml.Node, field string, errs *[]ValidationError) {
+	if n.Kind != yaml.ScalarNode || n.Value == "" {
+		*errs = append(*errs, errAt(n, field+" must be string"))
+		return
+	}
+	if !quantityRegex.MatchString(n.Value) {
+		*errs = append(*errs, errAt(n, fmt.Sprintf("%s has invalid format '%s'", field, n.Value)))
+	}
+}
+
+var (
+	// пути, монтирование поверх которых под restricted-профилем даёт предупреждение
+	sensitiveMountPaths    = []string{"/", "/etc", "/proc", "/sys", "/var/run/secrets"}
+	sensitiveMountPrefixes = []string{"/var/run/secrets/"}
+)
+
+// validateVolumeMounts проверяет volumeMounts контейнера; volumes — тома пода по имени.
+func validateVolumeMounts(c *yaml.Node, volumes map[string]*yaml.Node, errs *[]ValidationError) {
+	const field = "containers.volumeMounts"
+	_, mounts := getMap(c, "volumeMounts")
+	if mounts == nil || !expectType(mounts, yaml.SequenceNode, field, errs) {
+		return
+	}
+	used := map[string]*yaml.Node{}
+	for _, m := range mounts.Content {
+		if m.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(m, field+" must be array"))
+			continue
+		}
+		if _, name := getMap(m, "name"); name == nil {
+			*errs = append(*errs, errAt(m, field+".name is required"))
+		} else if expectString(name, field+".name", errs) && volumes != nil && volumes[name.Value] == nil {
+			*errs = append(*errs, errAt(name, fmt.Sprintf("%s.name '%s' does not match any spec.volumes", field, name.Value)))
+		}
+		if _, sub := getMap(m, "subPath"); sub != nil {
+			validateRelativePath(sub, field+".subPath", errs)
+		}
+		validateMountReadOnly(m, volumes, errs)
+
+		_, mp := getMap(m, "mountPath")
+		if mp == nil {
+			*errs = append(*errs, errAt(m, field+".mountPath is required"))
+			continue
+		}
+		if !expectString(mp, field+".mountPath", errs) {
+			continue
+		}
+		if !strings.HasPrefix(mp.Value, "/") {
+			*errs = append(*errs, errAt(mp, fmt.Sprintf("%s.mountPath has invalid format '%s'", field, mp.Value)))
+			continue
+		}
+		p := path.Clean(mp.Value)
+		if prev := used[p]; prev != nil {
+			*errs = append(*errs, errAt(mp, fmt.Sprintf("%s.mountPath '%s' conflicts with line %d", field, mp.Value, prev.Line)))
+		}
+		used[p] = mp
+		if activeProfile() == profileRestricted && (contains(sensitiveMountPaths, p) || hasAnyPrefix(p, sensitiveMountPrefixes)) {
+			*errs = append(*errs, restrictedAt(mp, fmt.Sprintf("%s.mountPath '%s' should not mount over a system path under restricted profile", field, mp.Value)))
+		}
+	}
+}
+
+// podVolumes возвращает тома пода по имени; nil, если spec.volumes задан некорректно.
+func podVolumes(spec *yaml.Node) map[string]*yaml.Node {
+	vols := map[string]*yaml.Node{}
+	_, list := getMap(spec, "volumes")
+	if list == nil {
+		return vols
+	}
+	if list.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, v := range list.Content {
+		if _, name := getMap(v, "name"); name != nil && name.Kind == yaml.ScalarNode {
+			vols[name.Value] = v
+		}
+	}
+	return vols
+}
+
+// validateMountReadOnly рекомендует readOnly: true для томов configMap и secret.
+func validateMountReadOnly(m *yaml.Node, volumes map[string]*yaml.Node, errs *[]ValidationError) {
+	const field = "containers.volumeMounts.readOnly"
+	_, ro := getMap(m, "readOnly")
+	if ro != nil && !expectBool(ro, field, errs) {
+		return
+	}
+	_, name := getMap(m, "name")
+	if name == nil || name.Kind != yaml.ScalarNode {
+		return
+	}
+	source := ""
+	for _, s := range []string{"configMap", "secret"} {
+		if k, _ := getMap(volumes[name.Value], s); k != nil {
+			source = s
+		}
+	}
+	if source == "" || (ro != nil && ro.Value == "true") {
+		return
+	}
+	at, fix := m, appendKeyFix(m, "readOnly", "true")
+	if ro != nil {
+		at, fix = ro, replaceFix(ro, "true")
+	}
+	e := warnAt(at, fmt.Sprintf("%s should be true for %s volume '%s'", field, source, name.Value))
+	e.Hint = "set readOnly: true"
+	e.Fix = fix
+	*errs = append(*errs, e)
+}
@@ -0,0 +1,164 @@
+package validator
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// размер очередей конвейера обхода каталогов по умолчанию (--buffer-size)
+const defaultBufferSize = 64
+
+// scanResult — файл, найденный при обходе, и результат его чтения и разбора
+type scanResult struct {
+	// номер файла в очереди чтения
+	seq  int
+	file string
+	m    *manifest
+	err  error
+}
+
+// hasDir сообщает, есть ли среди путей каталог.
+func hasDir(paths []string) bool {
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// listManifests обходит пути и возвращает найденные *.yaml/*.yml, отсортированные по
+// пути: порядок вывода не зависит ни от порядка аргументов, ни от обхода. Ошибки обхода —
+// элементы с err. В памяти остаются только пути, содержимое читает readManifests.
+func listManifests(paths []string, followSymlinks bool) []scanResult {
+	var files []scanResult
+	for _, p := range paths {
+		files = walkManifests(p, followSymlinks, files)
+	}
+	slices.SortStableFunc(files, func(a, b scanResult) int {
+		return strings.Compare(a.file, b.file)
+	})
+	return files
+}
+
+// readManifests читает и разбирает files в несколько потоков. Очереди между выдачей,
+// чтением и потребителем ограничены bufferSize, поэтому в памяти одновременно не больше
+// нескольких буферов файлов, каков бы ни был каталог. Результаты приходят в порядке files,
+// а не в порядке завершения чтения, чтобы вывод не зависел от планировщика.
+func readManifests(files []scanResult, bufferSize int) <-chan scanResult {
+	queue := make(chan scanResult, bufferSize)
+	read := make(chan scanResult, bufferSize)
+	out := make(chan scanResult, bufferSize)
+	// номеров в работе (от выдачи до отправки потребителю) не больше bufferSize:
+	// иначе reorder копит сколько угодно готовых файлов, пока ждёт медленный
+	slots := make(chan struct{}, bufferSize)
+	go func() {
+		defer close(queue)
+		for seq, r := range files {
+			slots <- struct{}{}
+			r.seq = seq
+			queue <- r
+		}
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range queue {
+				if r.err == nil {
+					r.m, r.err = readManifest(r.file)
+				}
+				read <- r
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(read)
+	}()
+	go reorder(read, out, slots)
+	return out
+}
+
+// reorder пересылает результаты из in в out по возрастанию seq и после отправки
+// освобождает место в slots. Ожидающих своей очереди не больше cap(slots).
+func reorder(in <-chan scanResult, out chan<- scanResult, slots <-chan struct{}) {
+	defer close(out)
+	pending := map[int]scanResult{}
+	next := 0
+	for r := range in {
+		pending[r.seq] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			out <- r
+			<-slots
+			next++
+		}
+	}
+}
+
+// walkManifests добавляет к files манифесты пути p; ошибки обхода тоже идут в files.
+func walkManifests(p string, followSymlinks bool, files []scanResult) []scanResult {
+	info, err := os.Stat(p)
+	if err != nil || !info.IsDir() {
+		return append(files, scanResult{file: p, err: err})
+	}
+	w := dirWalker{follow: followSymlinks, files: files, parents: map[string]bool{}}
+	w.walk(p)
+	return w.files
+}
+
+// dirWalker обходит каталог в порядке имён. Ссылки на каталоги раскрываются только
+// при follow; ссылка на один из родительских каталогов (цикл) пропускается.
+type dirWalker struct {
+	follow bool
+	files  []scanResult
+	// настоящие пути каталогов текущей ветки обхода
+	parents map[string]bool
+}
+
+func (w *dirWalker) walk(dir string) {
+	if w.follow {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			w.files = append(w.files, scanResult{file: dir, err: err})
+			return
+		}
+		if w.parents[real] {
+			return
+		}
+		w.parents[real] = true
+		defer delete(w.parents, real)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.files = append(w.files, scanResult{file: dir, err: err})
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		isDir := e.IsDir()
+		if w.follow && e.Type()&fs.ModeSymlink != 0 {
+			info, err := os.Stat(path)
+			if err != nil {
+				w.files = append(w.files, scanResult{file: path, err: err})
+				continue
+			}
+			isDir = info.IsDir()
+		}
+		if isDir {
+			w.walk(path)
+		} else if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			w.files = append(w.files, scanResult{file: path})
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Результаты обхода приходят в порядке имён при любом размере буфера, в том числе
+// когда ограничение на номера в работе меньше числа потоков чтения.
+func TestReadManifestsOrder(t *testing.T) {
+	dir := t.TempDir()
+	const n = 50
+	var want []string
+	for i := 0; i < n; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("m%03d.yaml", i))
+		if err := os.WriteFile(file, []byte("apiVersion: v1\nkind: ConfigMap\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, file)
+	}
+	for _, size := range []int{1, 2, defaultBufferSize} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			var got []string
+			for r := range readManifests(listManifests([]string{dir}, false), size) {
+				if r.err != nil {
+					t.Fatal(r.err)
+				}
+				got = append(got, r.file)
+			}
+			if len(got) != n {
+				t.Fatalf("got %d files, want %d", len(got), n)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("file %d = %s, want %s", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+// reorder не держит готовые результаты сверх выданных мест: пока первый номер не
+// пришёл, диспетчер не может выдать больше cap(slots) номеров.
+func TestReorderBounded(t *testing.T) {
+	const size = 3
+	in := make(chan scanResult, 10)
+	out := make(chan scanResult, 10)
+	slots := make(chan struct{}, size)
+	go reorder(in, out, slots)
+
+	// номера 1..size-1 готовы раньше 0
+	for seq := 0; seq < size; seq++ {
+		slots <- struct{}{}
+	}
+	for seq := 1; seq < size; seq++ {
+		in <- scanResult{seq: seq}
+	}
+	select {
+	case slots <- struct{}{}:
+		t.Fatal("a slot was free while seq 0 was still pending")
+	default:
+	}
+	in <- scanResult{seq: 0}
+	for seq := 0; seq < size; seq++ {
+		if r := <-out; r.seq != seq {
+			t.Fatalf("got seq %d, want %d", r.seq, seq)
+		}
+	}
+	close(in)
+}
@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// Флаг, с которым Bazel запускает постоянный воркер
+const persistentWorkerFlag = "--persistent_worker"
+
+// workRequest и workResponse — JSON-вариант протокола постоянных воркеров Bazel
+// (execution_requirements: requires-worker-protocol = json).
+type workRequest struct {
+	Arguments []string `json:"arguments"`
+	RequestID int      `json:"requestId"`
+}
+
+type workResponse struct {
+	ExitCode  int    `json:"exitCode"`
+	Output    string `json:"output"`
+	RequestID int    `json:"requestId"`
+}
+
+// workerStdin — stdin на время запроса: поток занят протоколом Bazel, и запрос, который
+// попробует его прочитать ("-", --stdin-batch, krm), получит ошибку вместо чужих байтов.
+type workerStdin struct {
+	used bool
+}
+
+func (s *workerStdin) Read([]byte) (int, error) {
+	s.used = true
+	return 0, errors.New("stdin is reserved for the worker protocol")
+}
+
+// runWorker обрабатывает запросы из r, пока Bazel не закроет stdin. startup — аргументы
+// запуска воркера без --persistent_worker; они добавляются перед аргументами каждого запроса.
+func runWorker(startup []string, r io.Reader, w io.Writer) int {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	enc := json.NewEncoder(w)
+	defer func(prev io.Reader) { stdin = prev }(stdin)
+	for {
+		var req workRequest
+		if err := dec.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0
+			}
+			return 2
+		}
+		var out bytes.Buffer
+		guard := &workerStdin{}
+		stdin = guard
+		code := run(append(append([]string(nil), startup...), req.Arguments...), &out)
+		if guard.used {
+			// часть вывода могла уйти в stderr воркера; ответ — только причина отказа
+			out.Reset()
+			out.WriteString("arguments read stdin, which the worker protocol uses; pass files instead\n")
+			code = 2
+		}
+		if err := enc.Encode(workResponse{ExitCode: code, Output: out.String(), RequestID: req.RequestID}); err != nil {
+			return 2
+		}
+	}
+}
+
+// expandFlagfiles заменяет аргументы "@file" и "--flagfile=file" содержимым файла,
+// по аргументу на строку, как их пишет Bazel.
+func expandFlagfiles(args []string) ([]string, error) {
+	var out []string
+	for _, a := range args {
+		path := ""
+		switch {
+		case strings.HasPrefix(a, "@") && len(a) > 1:
+			path = a[1:]
+		case strings.HasPrefix(a, "--flagfile="):
+			path = strings.TrimPrefix(a, "--flagfile=")
+		default:
+			out = append(out, a)
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+			if line = strings.TrimSuffix(line, "\r"); line != "" {
+				out = append(out, line)
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Запросы, читающие stdin, отвергаются по одному, и следующие запросы потока обрабатываются.
+func TestWorkerRejectsStdinArguments(t *testing.T) {
+	file := writeTestFile(t, t.TempDir(), "pod.yaml", testPod("app", "Vyp"))
+	const rejected = "arguments read stdin, which the worker protocol uses; pass files instead\n"
+	tests := []struct {
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{[]string{"-"}, 2, rejected},
+		{[]string{file, "-"}, 2, rejected},
+		{[]string{"--stdin-batch", "json"}, 2, rejected},
+		{[]string{"--stdin-batch=nul"}, 2, rejected},
+		{[]string{"krm"}, 2, rejected},
+		{[]string{"argocd"}, 2, rejected},
+		{[]string{file}, 1, "pod.yaml:6 os has unsupported value 'Vyp'\n"},
+	}
+	var in strings.Builder
+	for i, tt := range tests {
+		b, err := json.Marshal(workRequest{Arguments: tt.args, RequestID: i + 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		in.Write(b)
+		in.WriteString("\n")
+	}
+	var out strings.Builder
+	if code := runWorker(nil, strings.NewReader(in.String()), &out); code != 0 {
+		t.Fatalf("worker exit code = %d, want 0", code)
+	}
+	dec := json.NewDecoder(strings.NewReader(out.String()))
+	for i, tt := range tests {
+		var resp workResponse
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("response %d: %v", i+1, err)
+		}
+		got := fmt.Sprintf("%d %d %q", resp.RequestID, resp.ExitCode, resp.Output)
+		if want := fmt.Sprintf("%d %d %q", i+1, tt.wantCode, tt.wantOut); got != want {
+			t.Errorf("%v: response %s, want %s", tt.args, got, want)
+		}
+	}
+	if dec.More() {
+		t.Error("extra responses in the stream")
+	}
+	if stdin != os.Stdin {
+		t.Error("stdin is not restored after the worker stops")
+	}
+}
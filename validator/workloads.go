@@ -0,0 +1,235 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var intOrPercentRegex = regexp.MustCompile(`^[0-9]+%?$`)
+
+func validateDeploymentSpec(spec *yaml.Node, errs *[]ValidationError) {
+	validateWorkloadSpec(spec, errs)
+
+	// strategy (необязательное)
+	if _, st := getMap(spec, "strategy"); st != nil {
+		validateUpdateStrategy(st, "spec.strategy", []string{"RollingUpdate", "Recreate"}, errs)
+	}
+	validateIntFields(spec, "spec.", errs, "minReadySeconds", "progressDeadlineSeconds")
+}
+
+func validateStatefulSetSpec(spec *yaml.Node, errs *[]ValidationError) {
+	validateWorkloadSpec(spec, errs)
+
+	// updateStrategy (необязательное)
+	if _, st := getMap(spec, "updateStrategy"); st != nil {
+		validateUpdateStrategy(st, "spec.updateStrategy", []string{"RollingUpdate", "OnDelete"}, errs)
+	}
+	if _, pmp := getMap(spec, "podManagementPolicy"); pmp != nil {
+		if expectString(pmp, "spec.podManagementPolicy", errs) && pmp.Value != "OrderedReady" && pmp.Value != "Parallel" {
+			*errs = append(*errs, errAt(pmp, fmt.Sprintf("spec.podManagementPolicy has unsupported value '%s'", pmp.Value)))
+		}
+	}
+	if _, sn := getMap(spec, "serviceName"); sn != nil {
+		validateDNSName(sn, "spec.serviceName", errs)
+	}
+}
+
+// validateWorkloadSpec проверяет поля, общие для контроллеров: replicas, selector, template.
+func validateWorkloadSpec(spec *yaml.Node, errs *[]ValidationError) {
+	validateIntFields(spec, "spec.", errs, "replicas", "revisionHistoryLimit")
+
+	// selector (обязательное)
+	_, selector := getMap(spec, "selector")
+	if selector == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.selector is required"})
+	} else if expectType(selector, yaml.MappingNode, "spec.selector", errs) {
+		validateLabelSelector(selector, "spec.selector", errs)
+	}
+
+	// template (обязательное)
+	_, tpl := getMap(spec, "template")
+	if tpl == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.template is required"})
+		return
+	}
+	if !expectType(tpl, yaml.MappingNode, "spec.template", errs) {
+		return
+	}
+	var labels *yaml.Node
+	if _, meta := getMap(tpl, "metadata"); meta != nil && expectType(meta, yaml.MappingNode, "spec.template.metadata", errs) {
+		if _, labels = getMap(meta, "labels"); labels != nil {
+			validateLabels(labels, "spec.template.metadata.labels", errs)
+		}
+	}
+	if selector != nil && selector.Kind == yaml.MappingNode && !selectorMatches(selector, labels) {
+		*errs = append(*errs, errAt(selector, "spec.selector does not match spec.template.metadata.labels"))
+	}
+
+	_, podSpec := getMap(tpl, "spec")
+	if podSpec == nil {
+		*errs = append(*errs, ValidationError{Msg: "spec.template.spec is required"})
+	} else if expectType(podSpec, yaml.MappingNode, "spec.template.spec", errs) {
+		validatePodSpec(podSpec, errs)
+	}
+}
+
+func validateLabelSelector(sel *yaml.Node, field string, errs *[]ValidationError) {
+	_, ml := getMap(sel, "matchLabels")
+	_, me := getMap(sel, "matchExpressions")
+	if ml == nil && me == nil {
+		*errs = append(*errs, errAt(sel, field+" must have matchLabels or matchExpressions"))
+		return
+	}
+	if ml != nil {
+		validateLabels(ml, field+".matchLabels", errs)
+	}
+	if me == nil || !expectType(me, yaml.SequenceNode, field+".matchExpressions", errs) {
+		return
+	}
+	for _, e := range me.Content {
+		if e.Kind != yaml.MappingNode {
+			*errs = append(*errs, errAt(e, field+".matchExpressions must be array"))
+			continue
+		}
+		_, key := getMap(e, "key")
+		if key == nil {
+			*errs = append(*errs, ValidationError{Msg: field + ".matchExpressions.key is required"})
+		} else {
+			expectString(key, field+".matchExpressions.key", errs)
+		}
+		_, op := getMap(e, "operator")
+		_, values := getMap(e, "values")
+		if op == nil {
+			*errs = append(*errs, ValidationError{Msg: field + ".matchExpressions.operator is required"})
+			continue
+		}
+		if !expectString(op, field+".matchExpressions.operator", errs) {
+			continue
+		}
+		switch op.Value {
+		case "In", "NotIn":
+			if values == nil || values.Kind != yaml.SequenceNode || len(values.Content) == 0 {
+				*errs = append(*errs, errAt(op, fmt.Sprintf("%s.matchExpressions.values is required for operator '%s'", field, op.Value)))
+			}
+		case "Exists", "DoesNotExist":
+			if values != nil && values.Kind == yaml.SequenceNode && len(values.Content) > 0 {
+				*errs = append(*errs, errAt(values, fmt.Sprintf("%s.matchExpressions.values must be empty for operator '%s'", field, op.Value)))
+			}
+		default:
+			*errs = append(*errs, errAt(op, fmt.Sprintf("%s.matchExpressions.operator has unsupported value '%s'", field, op.Value)))
+		}
+	}
+}
+
+// selectorMatches вычисляет селектор на метках шаблона; некорректные части селектора пропускаются,
+// о них уже сообщает validateLabelSelector.
+func selectorMatches(sel, labels *yaml.Node) bool {
+	get := func(k string) (string, bool) {
+		if _, v := getMap(labels, k); v != nil {
+			return v.Value, true
+		}
+		return "", false
+	}
+	if _, ml := getMap(sel, "matchLabels"); ml != nil && ml.Kind == yaml.MappingNode {
+		for i := 0; i < len(ml.Content)-1; i += 2 {
+			if v, ok := get(ml.Content[i].Value); !ok || v != ml.Content[i+1].Value {
+				return false
+			}
+		}
+	}
+	_, me := getMap(sel, "matchExpressions")
+	if me == nil || me.Kind != yaml.SequenceNode {
+		return true
+	}
+	for _, e := range me.Content {
+		_, key := getMap(e, "key")
+		_, op := getMap(e, "operator")
+		if key == nil || op == nil {
+			continue
+		}
+		var values []string
+		if _, vs := getMap(e, "values"); vs != nil {
+			for _, v := range vs.Content {
+				values = append(values, v.Value)
+			}
+		}
+		v, ok := get(key.Value)
+		switch op.Value {
+		case "In":
+			if !ok || !contains(values, v) {
+				return false
+			}
+		case "NotIn":
+			if ok && contains(values, v) {
+				return false
+			}
+		case "Exists":
+			if !ok {
+				return false
+			}
+		case "DoesNotExist":
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func validateUpdateStrategy(st *yaml.Node, field string, types []string, errs *[]ValidationError) {
+	if !expectType(st, yaml.MappingNode, field, errs) {
+		return
+	}
+	typ := "RollingUpdate"
+	if _, t := getMap(st, "type"); t != nil && expectString(t, field+".type", errs) {
+		if !contains(types, t.Value) {
+			*errs = append(*errs, errAt(t, fmt.Sprintf("%s.type has unsupported value '%s'", field, t.Value)))
+			return
+		}
+		typ = t.Value
+	}
+	k, ru := getMap(st, "rollingUpdate")
+	if ru == nil {
+		return
+	}
+	if typ != "RollingUpdate" {
+		*errs = append(*errs, errAt(k, fmt.Sprintf("%s.rollingUpdate is not allowed when type is '%s'", field, typ)))
+		return
+	}
+	if !expectType(ru, yaml.MappingNode, field+".rollingUpdate", errs) {
+		return
+	}
+	for _, f := range []string{"maxSurge", "maxUnavailable"} {
+		if _, v := getMap(ru, f); v != nil {
+			if v.Kind != yaml.ScalarNode || !intOrPercentRegex.MatchString(v.Value) {
+				*errs = append(*errs, errAt(v, fmt.Sprintf("%s.rollingUpdate.%s has invalid format '%s'", field, f, v.Value)))
+			}
+		}
+	}
+	validateIntFields(ru, field+".rollingUpdate.", errs, "partition")
+}
+
+// validateIntRange проверяет целое в [min, max]; max < 0 — без верхней границы.
+func validateIntRange(n *yaml.Node, field string, min, max int, errs *[]ValidationError) {
+	short := field[strings.LastIndex(field, ".")+1:]
+	if n.Kind != yaml.ScalarNode {
+		*errs = append(*errs, errAt(n, short+" must be int"))
+		return
+	}
+	if scalarType(n) != "int" {
+		// текст сообщения закреплён автотестами построчно, подсказку к нему не добавляем
+		*errs = append(*errs, errAt(n, short+" must be int"))
+		return
+	}
+	checkOctal(n, field, errs)
+	val, err := strconv.Atoi(n.Value)
+	if err != nil {
+		*errs = append(*errs, errAt(n, short+" must be int"))
+	} else if val < min || (max >= 0 && val > max) {
+		*errs = append(*errs, errAt(n, short+" value out of range"))
+	}
+}
@@ -0,0 +1,32 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+// Строки "must be int" и "value out of range" автотесты сверяют целиком: без подсказок и суффиксов.
+func TestIntFieldLegacyLines(t *testing.T) {
+	tests := []struct {
+		port string
+		want string
+	}{
+		{`"8080"`, "pod.yaml:11 containerPort must be int"},
+		{"true", "pod.yaml:11 containerPort must be int"},
+		{"80.5", "pod.yaml:11 containerPort must be int"},
+		{"[80]", "pod.yaml:11 containerPort must be int"},
+		{"70000", "pod.yaml:11 containerPort value out of range"},
+		{"8080", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.port, func(t *testing.T) {
+			src := strings.Replace(testPod("app", "linux"), "      resources:\n",
+				"      ports:\n        - containerPort: "+tt.port+"\n      resources:\n", 1)
+			file := writeTestFile(t, t.TempDir(), "pod.yaml", src)
+			_, out := runCLI(t, file)
+			if got := strings.TrimSpace(out); got != tt.want {
+				t.Errorf("output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
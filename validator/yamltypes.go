@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yaml.v3 разбирает YAML 1.2, а kubectl и многие другие инструменты — YAML 1.1,
+// где у ряда "строк" другой тип.
+var (
+	yaml11Bools = []string{"y", "Y", "yes", "Yes", "YES", "n", "N", "no", "No", "NO",
+		"on", "On", "ON", "off", "Off", "OFF"}
+	// 1:30, 190:20:30 — в YAML 1.1 это числа по основанию 60
+	sexagesimalRegex = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+	// 0755 — восьмеричное число в YAML 1.1
+	octalRegex = regexp.MustCompile(`^[-+]?0[0-9_]+$`)
+)
+
+// булевы поля Kubernetes, в которых ищутся bool в стиле YAML 1.1
+var boolFields = []string{"privileged", "allowPrivilegeEscalation", "readOnlyRootFilesystem", "runAsNonRoot",
+	"hostNetwork", "hostPID", "hostIPC", "hostUsers", "shareProcessNamespace", "automountServiceAccountToken",
+	"enableServiceLinks", "setHostnameAsFQDN", "readOnly", "optional", "immutable", "stdin", "stdinOnce", "tty",
+	"suspend", "paused", "publishNotReadyAddresses", "allowVolumeExpansion"}
+
+// yaml11Bool — значение plain-скаляра, который YAML 1.1 читает как bool: yes, Off, True.
+// Канонические true и false не считаются.
+func yaml11Bool(n *yaml.Node) (value, ok bool) {
+	if n.Kind != yaml.ScalarNode || n.Style != 0 || n.Value == "true" || n.Value == "false" {
+		return false, false
+	}
+	switch n.Value {
+	case "y", "Y", "yes", "Yes", "YES", "on", "On", "ON", "True", "TRUE":
+		return true, true
+	case "n", "N", "no", "No", "NO", "off", "Off", "OFF", "False", "FALSE":
+		return false, true
+	}
+	return false, false
+}
+
+// validateBoolScalars предупреждает о неканонических bool в булевых полях документа:
+// kubectl (YAML 1.1) прочтёт yes как true, а парсеры YAML 1.2 — как строку.
+// Исправление заменяет значение на true или false.
+func validateBoolScalars(n *yaml.Node, field string, errs *[]ValidationError) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			f := joinField(field, k.Value)
+			if b, ok := yaml11Bool(v); ok && contains(boolFields, k.Value) {
+				msg := fmt.Sprintf("%s value '%s' is a bool in YAML 1.1 and a string in YAML 1.2", f, v.Value)
+				if v.ShortTag() == "!!bool" {
+					msg = fmt.Sprintf("%s value '%s' is not a canonical bool", f, v.Value)
+				}
+				e := warnAt(v, msg)
+				e.Hint = fmt.Sprintf("use %t", b)
+				e.Fix = replaceFix(v, strconv.FormatBool(b))
+				*errs = append(*errs, e)
+				continue
+			}
+			validateBoolScalars(v, f, errs)
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			validateBoolScalars(item, field, errs)
+		}
+	}
+}
+
+// formatValue — значение скаляра для проверок формата: у | и > скаляров отбрасывается
+// завершающий перевод строки, а внутренние сворачиваются в пробелы, как в > скаляре.
+func formatValue(n *yaml.Node) string {
+	if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		return n.Value
+	}
+	return strings.ReplaceAll(strings.TrimRight(n.Value, "\n"), "\n", " ")
+}
+
+func quoteHint(n *yaml.Node) string {
+	return fmt.Sprintf("quote the value: \"%s\"", n.Value)
+}
+
+// checkImplicitString предупреждает о plain-скалярах, которые парсер YAML 1.1 прочитает не как строку.
+func checkImplicitString(n *yaml.Node, field string, errs *[]ValidationError) {
+	if n.Style != 0 {
+		return
+	}
+	var got string
+	switch {
+	case contains(yaml11Bools, n.Value):
+		got = "bool"
+	case sexagesimalRegex.MatchString(n.Value):
+		got = "sexagesimal number"
+	default:
+		return
+	}
+	e := warnAt(n, fmt.Sprintf("%s value '%s' is a %s in YAML 1.1", field, n.Value, got))
+	e.Hint = quoteHint(n)
+	*errs = append(*errs, e)
+}
+
+// checkOctal предупреждает о целых с ведущим нулём: YAML 1.1 читает их как восьмеричные.
+func checkOctal(n *yaml.Node, field string, errs *[]ValidationError) {
+	if n == nil || n.Kind != yaml.ScalarNode || n.Style != 0 || !octalRegex.MatchString(n.Value) {
+		return
+	}
+	e := warnAt(n, fmt.Sprintf("%s '%s' has a leading zero and is octal in YAML 1.1", field, n.Value))
+	e.Hint = "remove the leading zero"
+	*errs = append(*errs, e)
+}